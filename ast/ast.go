@@ -0,0 +1,394 @@
+// Package ast declares the syntax tree of myc programs.
+package ast
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"myc/token"
+)
+
+// AST is a node of the syntax tree. Pos is the position of the first
+// character of the node and End the position just after it.
+type AST interface {
+	Pos() token.Pos
+	End() token.Pos
+}
+
+// Span holds the position of a node.
+type Span struct {
+	Start, Stop token.Pos
+}
+
+func (s Span) Pos() token.Pos {
+	return s.Start
+}
+
+func (s Span) End() token.Pos {
+	return s.Stop
+}
+
+// PosOf returns the position of node, which may be nil.
+func PosOf(node AST) token.Pos {
+	if node == nil {
+		return token.NoPos
+	}
+	return node.Pos()
+}
+
+type ASTProject struct {
+	Span
+	Imports  []ASTImport
+	StmtList AST
+}
+
+func (ast ASTProject) String() string {
+	return fmt.Sprintf("(imports %v,program %v)", ast.Imports, ast.StmtList)
+}
+
+type ASTImport struct {
+	Span
+	Path string
+}
+
+func (ast ASTImport) String() string {
+	return fmt.Sprintf("(import %v)", ast.Path)
+}
+
+// ASTNumber is a number literal. Kind is "int" or "float" and the value
+// is in Int or Float.
+type ASTNumber struct {
+	Span
+	Lit   string
+	Kind  string
+	Int   int64
+	Float float64
+}
+
+func (ast ASTNumber) String() string {
+	return fmt.Sprintf("[N:%v]", ast.Lit)
+}
+
+// Literal returns the number without separators or suffix, written so that
+// C and Go read it as a number of the same kind.
+func (ast ASTNumber) Literal() string {
+	var lit = strings.Replace(ast.Lit, "_", "", -1)
+	if strings.HasSuffix(lit, "i") || ast.Kind == "float" && strings.HasSuffix(lit, "f") {
+		lit = lit[:len(lit)-1]
+	}
+	if ast.Kind == "float" && !strings.ContainsAny(lit, ".eE") {
+		lit += ".0"
+	}
+	return lit
+}
+
+// NewNumber returns the literal num as an ASTNumber. It only reports values
+// out of range, the lexer has reported malformed literals and they are 0.
+func NewNumber(s Span, num string) (ASTNumber, error) {
+	var n = ASTNumber{Span: s, Lit: num, Kind: "int"}
+	var lit = strings.Replace(num, "_", "", -1)
+	var prefixed = len(lit) > 1 && lit[0] == '0' && strings.ContainsRune("xXbBoO", rune(lit[1]))
+	if !prefixed {
+		switch lit[len(lit)-1] {
+		case 'i':
+			lit = lit[:len(lit)-1]
+			if strings.ContainsAny(lit, ".eE") {
+				return n, fmt.Errorf("float literal %s has an int suffix", num)
+			}
+		case 'f':
+			lit = lit[:len(lit)-1]
+			n.Kind = "float"
+		}
+		if strings.ContainsAny(lit, ".eE") {
+			n.Kind = "float"
+		}
+	}
+	var err error
+	if n.Kind == "float" {
+		n.Float, err = strconv.ParseFloat(lit, 64)
+	} else {
+		n.Int, err = strconv.ParseInt(lit, 0, 64)
+	}
+	if e, ok := err.(*strconv.NumError); ok && e.Err == strconv.ErrRange {
+		return n, fmt.Errorf("%s literal %s overflows %s", n.Kind, num, n.Kind)
+	}
+	return n, nil
+}
+
+type ASTString struct {
+	Span
+	Value string
+	Lit   string // the literal as written in the source
+}
+
+func (ast ASTString) String() string {
+	return fmt.Sprintf("[S:%v]", ast.Value)
+}
+
+type ASTBool struct {
+	Span
+	Value bool
+}
+
+func (ast ASTBool) String() string {
+	return fmt.Sprintf("[B:%v]", ast.Value)
+}
+
+type ASTUnaryOp struct {
+	Span
+	Op      string
+	Operand AST
+}
+
+func (ast ASTUnaryOp) String() string {
+	return fmt.Sprintf("(op %v %v)", ast.Op, ast.Operand)
+}
+
+type ASTBinaryOp struct {
+	Span
+	Left  AST
+	Op    string
+	Right AST
+}
+
+func (ast ASTBinaryOp) String() string {
+	return fmt.Sprintf("(op %v %v %v)", ast.Left, ast.Op, ast.Right)
+}
+
+type ASTVariable struct {
+	Span
+	Name string
+	Type string // type
+}
+
+func (ast ASTVariable) String() string {
+	return fmt.Sprintf("[V:%v:%v]", ast.Name, ast.Type)
+}
+
+// type ASTType struct{}
+
+type ASTStmt struct {
+	Span
+	List []AST
+}
+
+func (ast ASTStmt) String() string {
+	return fmt.Sprintf("(stmt %v)", ast.List)
+}
+
+type ASTAssign struct {
+	Span
+	Left      []ASTVariable
+	Op        string
+	Right     []AST
+	IsDefined bool
+}
+
+func (ast ASTAssign) String() string {
+	return fmt.Sprintf("(assign %v %v(%v) %v)", ast.Left, ast.Op, ast.IsDefined, ast.Right)
+}
+
+type ASTBranch struct {
+	Span
+	Cond AST
+	Then AST
+	Else AST
+}
+
+func (ast ASTBranch) String() string {
+	return fmt.Sprintf("(branch %v %v %v)", ast.Cond, ast.Then, ast.Else)
+}
+
+type ASTWhile struct {
+	Span
+	Cond AST
+	Body AST
+}
+
+func (ast ASTWhile) String() string {
+	return fmt.Sprintf("(while %v %v)", ast.Cond, ast.Body)
+}
+
+// ASTFor is a C style loop, every part but the body may be nil.
+type ASTFor struct {
+	Span
+	Init AST
+	Cond AST
+	Post AST
+	Body AST
+}
+
+func (ast ASTFor) String() string {
+	return fmt.Sprintf("(for %v; %v; %v %v)", ast.Init, ast.Cond, ast.Post, ast.Body)
+}
+
+// ASTRange counts Key from From up to To, To itself is not included.
+type ASTRange struct {
+	Span
+	Key  ASTVariable
+	From AST
+	To   AST
+	Body AST
+}
+
+func (ast ASTRange) String() string {
+	return fmt.Sprintf("(range %v %v..%v %v)", ast.Key, ast.From, ast.To, ast.Body)
+}
+
+type ASTBreak struct {
+	Span
+}
+
+func (ast ASTBreak) String() string {
+	return "(BREAK)"
+}
+
+type ASTContinue struct {
+	Span
+}
+
+func (ast ASTContinue) String() string {
+	return "(CONTINUE)"
+}
+
+type ASTFunction struct {
+	Span
+	DocComments []token.Comment // the comments directly above the function
+	Name        ASTVariable
+	Params      []ASTVariable
+	Results     []ASTVariable
+	Body        AST
+}
+
+// Doc returns the text of the doc comment of the function without the
+// comment markers.
+func (ast ASTFunction) Doc() string {
+	var lines []string
+	for _, c := range ast.DocComments {
+		if strings.HasPrefix(c.Text, "//") {
+			lines = append(lines, strings.TrimPrefix(c.Text[2:], " "))
+			continue
+		}
+		var text = strings.TrimSuffix(strings.TrimPrefix(c.Text, "/*"), "*/")
+		lines = append(lines, strings.Split(strings.TrimSpace(text), "\n")...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (ast ASTFunction) String() string {
+	return fmt.Sprintf("(def_func %v (%v) (%v) %v)", ast.Name, ast.Params, ast.Results, ast.Body)
+}
+
+type ASTCallFunc struct {
+	Span
+	Name ASTVariable
+	Args []AST
+}
+
+func (ast ASTCallFunc) String() string {
+	return fmt.Sprintf("(call_func %v (%v))", ast.Name, ast.Args)
+}
+
+type ASTReturn struct {
+	Span
+	Exprs []AST
+	Error string
+}
+
+func (ast ASTReturn) String() string {
+	return fmt.Sprintf("(return %v %s)", ast.Exprs, ast.Error)
+}
+
+// ASTError stands in for a statement with syntax errors.
+type ASTError struct {
+	Span
+}
+
+func (ast ASTError) String() string {
+	return "(ERROR)"
+}
+
+type ASTEmpty struct {
+	Span
+}
+
+func (ast ASTEmpty) String() string {
+	return "(VOID)"
+}
+
+// Prec is the precedence of the binary operators, higher binds tighter.
+// The keywords and, or, not are parsed as &&, ||, !.
+var Prec = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"==": 3, "!=": 3, "<": 3, "<=": 3, ">": 3, ">=": 3,
+	"<<": 4, ">>": 4, "&": 4, "^": 4, "|": 4,
+	"+": 5, "-": 5,
+	"*": 6, "/": 6, "%": 6,
+	"as": 7,
+}
+
+// UnaryPrec is the precedence of the unary operators.
+const UnaryPrec = 8
+
+// IsExpr reports whether node is an expression.
+func IsExpr(node AST) bool {
+	switch node.(type) {
+	case ASTNumber, ASTString, ASTBool, ASTVariable, ASTUnaryOp, ASTBinaryOp, ASTCallFunc:
+		return true
+	}
+	return false
+}
+
+// ExprString returns an expression as it is written in the source, for use
+// in messages. Other nodes are written as their kind in parentheses, exp.
+// (assign).
+func ExprString(node AST) string {
+	var buf strings.Builder
+	writeExpr(&buf, node, 0)
+	return buf.String()
+}
+
+// writeExpr writes node to buf, in parentheses when it binds looser than
+// prec.
+func writeExpr(buf *strings.Builder, node AST, prec int) {
+	switch node := node.(type) {
+	case ASTBinaryOp:
+		var p = Prec[node.Op]
+		if p < prec {
+			buf.WriteString("(")
+			defer buf.WriteString(")")
+		}
+		writeExpr(buf, node.Left, p)
+		buf.WriteString(" " + node.Op + " ")
+		writeExpr(buf, node.Right, p+1)
+	case ASTUnaryOp:
+		buf.WriteString(node.Op)
+		writeExpr(buf, node.Operand, UnaryPrec)
+	case ASTNumber:
+		buf.WriteString(node.Lit)
+	case ASTString:
+		if node.Lit != "" {
+			buf.WriteString(node.Lit)
+		} else {
+			buf.WriteString(strconv.Quote(node.Value))
+		}
+	case ASTBool:
+		buf.WriteString(strconv.FormatBool(node.Value))
+	case ASTVariable:
+		buf.WriteString(node.Name)
+	case ASTCallFunc:
+		buf.WriteString(node.Name.Name + "(")
+		for i, a := range node.Args {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			writeExpr(buf, a, 0)
+		}
+		buf.WriteString(")")
+	default:
+		var kind = strings.TrimPrefix(fmt.Sprintf("%T", node), "ast.AST")
+		buf.WriteString("(" + strings.ToLower(kind) + ")")
+	}
+}
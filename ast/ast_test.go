@@ -0,0 +1,67 @@
+package ast
+
+import "testing"
+
+func TestNewNumber(t *testing.T) {
+	var tests = []struct {
+		num  string
+		kind string
+		i    int64
+		f    float64
+		err  string
+	}{
+		{"42", "int", 42, 0, ""},
+		{"1_000", "int", 1000, 0, ""},
+		{"0x1f", "int", 31, 0, ""},
+		{"0b101", "int", 5, 0, ""},
+		{"0o17", "int", 15, 0, ""},
+		{"7i", "int", 7, 0, ""},
+		{"3000000000", "int", 3000000000, 0, ""},
+		{"9223372036854775807", "int", 9223372036854775807, 0, ""},
+		{"2.5", "float", 0, 2.5, ""},
+		{"1e3", "float", 0, 1000, ""},
+		{"2f", "float", 0, 2, ""},
+		{"0x10f", "int", 271, 0, ""},
+		{"9223372036854775808", "int", 0, 0, "int literal 9223372036854775808 overflows int"},
+		{"1e400", "float", 0, 0, "float literal 1e400 overflows float"},
+		{"1.5i", "int", 0, 0, "float literal 1.5i has an int suffix"},
+	}
+	for _, test := range tests {
+		n, err := NewNumber(Span{}, test.num)
+		if err != nil || test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%s: got error %v, want %q", test.num, err, test.err)
+			}
+			continue
+		}
+		if n.Kind != test.kind || n.Int != test.i || n.Float != test.f {
+			t.Errorf("%s: got %s %d %g, want %s %d %g", test.num, n.Kind, n.Int, n.Float, test.kind, test.i, test.f)
+		}
+	}
+}
+
+func TestExprString(t *testing.T) {
+	var one, two = ASTNumber{Lit: "1"}, ASTNumber{Lit: "0x2"}
+	var x = ASTVariable{Name: "x"}
+	var tests = []struct {
+		node AST
+		want string
+	}{
+		{one, "1"},
+		{ASTString{Lit: "`a`", Value: "a"}, "`a`"},
+		{ASTString{Value: "a\n"}, `"a\n"`},
+		{ASTBool{Value: true}, "true"},
+		{ASTBinaryOp{Left: one, Op: "+", Right: ASTBinaryOp{Left: two, Op: "*", Right: x}}, "1 + 0x2 * x"},
+		{ASTBinaryOp{Left: ASTBinaryOp{Left: one, Op: "+", Right: two}, Op: "*", Right: x}, "(1 + 0x2) * x"},
+		{ASTBinaryOp{Left: one, Op: "-", Right: ASTBinaryOp{Left: two, Op: "-", Right: x}}, "1 - (0x2 - x)"},
+		{ASTUnaryOp{Op: "-", Operand: ASTBinaryOp{Left: one, Op: "as", Right: ASTVariable{Name: "float"}}}, "-(1 as float)"},
+		{ASTUnaryOp{Op: "!", Operand: x}, "!x"},
+		{ASTCallFunc{Name: ASTVariable{Name: "m.f"}, Args: []AST{one, ASTBinaryOp{Left: x, Op: "&&", Right: x}}}, "m.f(1, x && x)"},
+		{ASTAssign{}, "(assign)"},
+	}
+	for _, test := range tests {
+		if got := ExprString(test.node); got != test.want {
+			t.Errorf("ExprString(%v) = %q, want %q", test.node, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// errInterrupt is returned by readLine when the line is cancelled with
+// ctrl-C.
+var errInterrupt = errors.New("interrupt")
+
+// lineEditor reads lines with editing and history when the input is a
+// terminal, and plain lines otherwise.
+//
+// The cursor is moved with the arrow keys, home and end or ctrl-B, ctrl-F,
+// ctrl-A and ctrl-E. Ctrl-K, ctrl-U and ctrl-W delete to the end of the
+// line, to its start and the word before the cursor. The up and down keys
+// or ctrl-P and ctrl-N go through the lines read before.
+type lineEditor struct {
+	in       *bufio.Reader
+	out      io.Writer
+	fd       int
+	terminal bool
+	history  []string
+}
+
+func newLineEditor(in *os.File, out io.Writer) *lineEditor {
+	var e = &lineEditor{in: bufio.NewReader(in), out: out, fd: int(in.Fd())}
+	if restore, err := makeRaw(e.fd); err == nil {
+		restore()
+		e.terminal = true
+	}
+	return e
+}
+
+func ctrl(c rune) rune {
+	return c & 0x1f
+}
+
+// keys of the escape sequences
+const (
+	keyUp rune = -1 - iota
+	keyDown
+	keyRight
+	keyLeft
+	keyHome
+	keyEnd
+	keyDelete
+	keyUnknown
+)
+
+// readLine reads a line, on a terminal prompt is shown in front of it. It
+// returns io.EOF at the end of the input or for ctrl-D on an empty line.
+func (e *lineEditor) readLine(prompt string) (string, error) {
+	if !e.terminal {
+		line, err := e.in.ReadString('\n')
+		if err == io.EOF && line != "" {
+			err = nil
+		}
+		return strings.TrimRight(line, "\r\n"), err
+	}
+	restore, err := makeRaw(e.fd)
+	if err != nil {
+		return "", err
+	}
+	defer restore()
+
+	var line []rune
+	var pos int                 // of the cursor in line
+	var hist = len(e.history)   // position in the history
+	var saved []rune            // the new line while the history is shown
+	var history = func(d int) { // moves d lines through the history
+		var i = hist + d
+		if i < 0 || i > len(e.history) {
+			return
+		}
+		if hist == len(e.history) {
+			saved = line
+		}
+		hist = i
+		if i == len(e.history) {
+			line = saved
+		} else {
+			line = []rune(e.history[i])
+		}
+		pos = len(line)
+	}
+
+	fmt.Fprint(e.out, prompt)
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		if r == 0x1b {
+			r = e.escape()
+		}
+		switch r {
+		case '\r', '\n':
+			fmt.Fprint(e.out, "\r\n")
+			var s = string(line)
+			if strings.TrimSpace(s) != "" && (len(e.history) == 0 || e.history[len(e.history)-1] != s) {
+				e.history = append(e.history, s)
+			}
+			return s, nil
+		case ctrl('C'):
+			fmt.Fprint(e.out, "^C\r\n")
+			return "", errInterrupt
+		case ctrl('D'):
+			if len(line) == 0 {
+				fmt.Fprint(e.out, "\r\n")
+				return "", io.EOF
+			}
+			fallthrough
+		case keyDelete:
+			if pos < len(line) {
+				line = append(line[:pos], line[pos+1:]...)
+			}
+		case 0x7f, ctrl('H'):
+			if pos > 0 {
+				line = append(line[:pos-1], line[pos:]...)
+				pos--
+			}
+		case ctrl('A'), keyHome:
+			pos = 0
+		case ctrl('E'), keyEnd:
+			pos = len(line)
+		case ctrl('B'), keyLeft:
+			if pos > 0 {
+				pos--
+			}
+		case ctrl('F'), keyRight:
+			if pos < len(line) {
+				pos++
+			}
+		case ctrl('K'):
+			line = line[:pos]
+		case ctrl('U'):
+			line = append([]rune{}, line[pos:]...)
+			pos = 0
+		case ctrl('W'):
+			var i = pos
+			for i > 0 && line[i-1] == ' ' {
+				i--
+			}
+			for i > 0 && line[i-1] != ' ' {
+				i--
+			}
+			line = append(line[:i], line[pos:]...)
+			pos = i
+		case ctrl('L'):
+			fmt.Fprint(e.out, "\x1b[H\x1b[2J")
+		case ctrl('P'), keyUp:
+			history(-1)
+		case ctrl('N'), keyDown:
+			history(1)
+		default:
+			if r == '\t' || r >= ' ' {
+				line = append(line[:pos], append([]rune{r}, line[pos:]...)...)
+				pos++
+			}
+		}
+		// the line is drawn again with the cursor at its column
+		fmt.Fprintf(e.out, "\r%s%s\x1b[K\r", prompt, strings.Replace(string(line), "\t", "    ", -1))
+		if n := width([]rune(prompt)) + width(line[:pos]); n > 0 {
+			fmt.Fprintf(e.out, "\x1b[%dC", n)
+		}
+	}
+}
+
+// escape reads the rest of an escape sequence and returns its key.
+func (e *lineEditor) escape() rune {
+	r, _, err := e.in.ReadRune()
+	if err != nil || r != '[' && r != 'O' {
+		return keyUnknown
+	}
+	var params []rune
+	for {
+		r, _, err = e.in.ReadRune()
+		if err != nil {
+			return keyUnknown
+		}
+		if r >= 0x40 && r <= 0x7e {
+			break
+		}
+		params = append(params, r)
+	}
+	switch r {
+	case 'A':
+		return keyUp
+	case 'B':
+		return keyDown
+	case 'C':
+		return keyRight
+	case 'D':
+		return keyLeft
+	case 'H':
+		return keyHome
+	case 'F':
+		return keyEnd
+	case '~':
+		switch string(params) {
+		case "1", "7":
+			return keyHome
+		case "4", "8":
+			return keyEnd
+		case "3":
+			return keyDelete
+		}
+	}
+	return keyUnknown
+}
+
+// width returns the number of columns s takes, tabs are shown as four
+// spaces and wide runes take two columns.
+func width(s []rune) int {
+	var n int
+	for _, r := range s {
+		switch {
+		case r == '\t':
+			n += 4
+		case wide(r):
+			n += 2
+		default:
+			n++
+		}
+	}
+	return n
+}
+
+// wideRanges are the ranges of runes with the East Asian Width W or F,
+// mostly CJK characters, Hangul, fullwidth forms and emoji.
+var wideRanges = [][2]rune{
+	{0x1100, 0x115f}, {0x231a, 0x231b}, {0x2329, 0x232a}, {0x23e9, 0x23ec},
+	{0x23f0, 0x23f0}, {0x23f3, 0x23f3}, {0x25fd, 0x25fe}, {0x2614, 0x2615},
+	{0x2648, 0x2653}, {0x267f, 0x267f}, {0x2693, 0x2693}, {0x26a1, 0x26a1},
+	{0x26aa, 0x26ab}, {0x26bd, 0x26be}, {0x26c4, 0x26c5}, {0x26ce, 0x26ce},
+	{0x26d4, 0x26d4}, {0x26ea, 0x26ea}, {0x26f2, 0x26f3}, {0x26f5, 0x26f5},
+	{0x26fa, 0x26fa}, {0x26fd, 0x26fd}, {0x2705, 0x2705}, {0x270a, 0x270b},
+	{0x2728, 0x2728}, {0x274c, 0x274c}, {0x274e, 0x274e}, {0x2753, 0x2755},
+	{0x2757, 0x2757}, {0x2795, 0x2797}, {0x27b0, 0x27b0}, {0x27bf, 0x27bf},
+	{0x2b1b, 0x2b1c}, {0x2b50, 0x2b50}, {0x2b55, 0x2b55}, {0x2e80, 0x303e},
+	{0x3041, 0x33ff}, {0x3400, 0x4dbf}, {0x4e00, 0x9fff}, {0xa000, 0xa4cf},
+	{0xa960, 0xa97f}, {0xac00, 0xd7a3}, {0xf900, 0xfaff}, {0xfe10, 0xfe19},
+	{0xfe30, 0xfe6f}, {0xff00, 0xff60}, {0xffe0, 0xffe6}, {0x16fe0, 0x16fe4},
+	{0x17000, 0x18cff}, {0x1b000, 0x1b2ff}, {0x1f004, 0x1f004}, {0x1f0cf, 0x1f0cf},
+	{0x1f18e, 0x1f18e}, {0x1f191, 0x1f19a}, {0x1f200, 0x1f251}, {0x1f300, 0x1f320},
+	{0x1f32d, 0x1f335}, {0x1f337, 0x1f37c}, {0x1f37e, 0x1f393}, {0x1f3a0, 0x1f3ca},
+	{0x1f3cf, 0x1f3d3}, {0x1f3e0, 0x1f3f0}, {0x1f3f4, 0x1f3f4}, {0x1f3f8, 0x1f43e},
+	{0x1f440, 0x1f440}, {0x1f442, 0x1f4fc}, {0x1f4ff, 0x1f53d}, {0x1f54b, 0x1f54e},
+	{0x1f550, 0x1f567}, {0x1f57a, 0x1f57a}, {0x1f595, 0x1f596}, {0x1f5a4, 0x1f5a4},
+	{0x1f5fb, 0x1f64f}, {0x1f680, 0x1f6c5}, {0x1f6cc, 0x1f6cc}, {0x1f6d0, 0x1f6d2},
+	{0x1f6d5, 0x1f6d7}, {0x1f6eb, 0x1f6ec}, {0x1f6f4, 0x1f6fc}, {0x1f7e0, 0x1f7eb},
+	{0x1f90c, 0x1f93a}, {0x1f93c, 0x1f945}, {0x1f947, 0x1f9ff}, {0x1fa70, 0x1faff},
+	{0x20000, 0x2fffd}, {0x30000, 0x3fffd},
+}
+
+// wide reports whether r takes two columns on a terminal.
+func wide(r rune) bool {
+	var i = sort.Search(len(wideRanges), func(i int) bool { return wideRanges[i][1] >= r })
+	return i < len(wideRanges) && wideRanges[i][0] <= r
+}
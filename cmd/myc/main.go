@@ -0,0 +1,397 @@
+// Command myc runs, compiles and formats myc programs.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"myc/ast"
+	"myc/codegen"
+	"myc/diag"
+	"myc/format"
+	"myc/interp"
+	"myc/lexer"
+	"myc/parser"
+	"myc/token"
+	"myc/trace"
+	"myc/types"
+)
+
+const usage = `myc is a tool for running and compiling myc programs.
+
+Usage:
+
+	myc <command> [arguments] <file>...
+
+The commands are:
+
+	run     run programs with the interpreter
+	build   compile programs to C or Go source
+	tokens  print the tokens of programs
+	ast     print the syntax tree of programs
+	check   report syntax and type errors in programs without running them
+	fmt     format programs
+	repl    run statements as they are typed
+
+A file named "-" is read from standard input.
+`
+
+// exit codes
+const (
+	exitOK    = 0
+	exitError = 1 // the program has errors
+	exitUsage = 2
+)
+
+var commands = map[string]func(args []string) int{
+	"run":    runCmd,
+	"build":  buildCmd,
+	"tokens": tokensCmd,
+	"ast":    astCmd,
+	"check":  checkCmd,
+	"fmt":    fmtCmd,
+	"repl":   replCmd,
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(exitUsage)
+	}
+	var name = os.Args[1]
+	if name == "help" || name == "-h" || name == "--help" {
+		fmt.Fprint(os.Stdout, usage)
+		return
+	}
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "myc %s: unknown command\nRun 'myc help' for usage.\n", name)
+		os.Exit(exitUsage)
+	}
+	os.Exit(cmd(os.Args[2:]))
+}
+
+func newFlagSet(name, args string) *flag.FlagSet {
+	var fs = flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: myc %s %s\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// traceFlag adds the -trace flag to fs.
+func traceFlag(fs *flag.FlagSet) *trace.Kind {
+	var kinds trace.Kind
+	fs.Var(&kinds, "trace", "print the `events` of the lexer, parser or interpreter to standard error, a list of lex, parse and exec")
+	return &kinds
+}
+
+// parseFlags parses the flags of a command that needs at least one file.
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, bool) {
+	if err := fs.Parse(args); err != nil {
+		return nil, false
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return nil, false
+	}
+	return fs.Args(), true
+}
+
+// open opens the file name, - is standard input.
+func open(name string) (io.ReadCloser, error) {
+	if name == "-" {
+		return ioutil.NopCloser(os.Stdin), nil
+	}
+	return os.Open(name)
+}
+
+// lex returns the tokens of the source read from r, tr may be nil.
+func lex(file *token.File, r io.Reader, tr trace.Tracer) ([]*token.Token, error) {
+	var l = lexer.NewReaderLexer(file, r)
+	l.SetTracer(tr)
+	var tokens = l.LexerToken()
+	return tokens, l.Diagnostics().Err()
+}
+
+// parse parses the source read from r, the returned error holds the
+// diagnostics of both the lexer and the parser. tr may be nil.
+func parse(file *token.File, r io.Reader, tr trace.Tracer) (ast.AST, error) {
+	var l = lexer.NewReaderLexer(file, r)
+	l.SetTracer(tr)
+	var p = parser.NewLexerParse(l)
+	p.SetTracer(tr)
+	var node = p.Parse()
+	return node, append(l.Diagnostics(), p.Diagnostics()...).Err()
+}
+
+// typecheck parses and type checks the source read from r.
+func typecheck(file *token.File, r io.Reader, tr trace.Tracer) (ast.AST, *types.TypeInfo, error) {
+	node, err := parse(file, r, tr)
+	if err != nil {
+		return nil, nil, err
+	}
+	var tc = types.NewTypeCheckVisitor(node)
+	err = tc.Exec()
+	return node, tc.Info(), err
+}
+
+// report prints err, diagnostics are shown with their source line.
+func report(file *token.File, src []byte, err error) {
+	var diags diag.Diagnostics
+	switch err := err.(type) {
+	case diag.Diagnostics:
+		diags = err
+	case diag.Diagnostic:
+		diags = diag.Diagnostics{err}
+	default:
+		fmt.Fprintf(os.Stderr, "%s: %v\n", file.Name(), err)
+		return
+	}
+	for i := range diags {
+		diags[i].File = file.Name()
+		diags[i].Resolve(file)
+	}
+	diags.Sort()
+	for _, d := range diags {
+		d.Render(os.Stderr, src)
+	}
+}
+
+// each runs fn for every file and reports the errors it returns. fn reads
+// the file from r as far as it needs it, what it read is kept to show the
+// source lines of the diagnostics.
+func each(files []string, fn func(file *token.File, r io.Reader) error) int {
+	var code = exitOK
+	var fset = token.NewFileSet()
+	for _, name := range files {
+		var src bytes.Buffer
+		f, err := open(name)
+		if name == "-" {
+			name = "<stdin>"
+		}
+		var file = fset.AddFile(name, 0)
+		if err == nil {
+			err = fn(file, io.TeeReader(f, &src))
+			f.Close()
+		}
+		if err != nil {
+			report(file, src.Bytes(), err)
+			code = exitError
+		}
+	}
+	return code
+}
+
+func runCmd(args []string) int {
+	var fs = newFlagSet("run", "[-allow=os] [-trace=lex,parse,exec] <file>...")
+	var events = traceFlag(fs)
+	var allow = fs.String("allow", "", "comma separated restricted modules programs may import, os for files")
+	files, ok := parseFlags(fs, args)
+	if !ok {
+		return exitUsage
+	}
+	// the exit status is the one main returned, of the last program that
+	// returned one
+	var status = exitOK
+	var code = each(files, func(file *token.File, r io.Reader) error {
+		var tr = trace.New(os.Stderr, file, *events)
+		node, err := parse(file, r, tr)
+		if err != nil {
+			return err
+		}
+		var ev = interp.NewExecVisitor(node)
+		ev.SetTracer(tr)
+		if *allow != "" {
+			ev.Allow(strings.Split(*allow, ",")...)
+		}
+		if err := ev.Exec(); err != nil {
+			return err
+		}
+		if ev.Status() != 0 {
+			status = ev.Status()
+		}
+		return nil
+	})
+	if code != exitOK {
+		return code
+	}
+	return status
+}
+
+func buildCmd(args []string) int {
+	var fs = newFlagSet("build", "[-target=c|go] [-o output] [-trace=lex,parse] <file>...")
+	var events = traceFlag(fs)
+	var target = fs.String("target", "c", "output language, c or go")
+	var output = fs.String("o", "", "output file, - for standard output")
+	files, ok := parseFlags(fs, args)
+	if !ok {
+		return exitUsage
+	}
+	if *target != "c" && *target != "go" {
+		fmt.Fprintf(os.Stderr, "myc build: unknown target %q\n", *target)
+		return exitUsage
+	}
+	if *output != "" && len(files) > 1 {
+		fmt.Fprintln(os.Stderr, "myc build: -o can only be used with a single file")
+		return exitUsage
+	}
+	return each(files, func(file *token.File, r io.Reader) error {
+		node, info, err := typecheck(file, r, trace.New(os.Stderr, file, *events))
+		if err != nil {
+			return err
+		}
+		// the output is only written when the whole program compiles, a
+		// failed build leaves an existing file alone
+		var buf bytes.Buffer
+		if *target == "go" {
+			err = codegen.NewExportGoVisitor(node, info, &buf).Exec()
+		} else {
+			err = codegen.NewExportCVisitor(node, info, &buf).Exec()
+		}
+		if err != nil {
+			return err
+		}
+		var out = *output
+		if out == "" {
+			out = strings.TrimSuffix(file.Name(), filepath.Ext(file.Name())) + "." + *target
+			if file.Name() == "<stdin>" {
+				out = "-"
+			}
+		}
+		if out == "-" {
+			_, err = os.Stdout.Write(buf.Bytes())
+			return err
+		}
+		return ioutil.WriteFile(out, buf.Bytes(), 0666)
+	})
+}
+
+func tokensCmd(args []string) int {
+	var fs = newFlagSet("tokens", "[-trace=lex] <file>...")
+	var events = traceFlag(fs)
+	files, ok := parseFlags(fs, args)
+	if !ok {
+		return exitUsage
+	}
+	return each(files, func(file *token.File, r io.Reader) error {
+		tokens, err := lex(file, r, trace.New(os.Stderr, file, *events))
+		if err != nil {
+			return err
+		}
+		for _, t := range tokens {
+			fmt.Println(t)
+		}
+		return nil
+	})
+}
+
+func astCmd(args []string) int {
+	var fs = newFlagSet("ast", "[-trace=lex,parse] <file>...")
+	var events = traceFlag(fs)
+	files, ok := parseFlags(fs, args)
+	if !ok {
+		return exitUsage
+	}
+	return each(files, func(file *token.File, r io.Reader) error {
+		node, err := parse(file, r, trace.New(os.Stderr, file, *events))
+		if err != nil {
+			return err
+		}
+		fmt.Println(node)
+		return nil
+	})
+}
+
+func checkCmd(args []string) int {
+	var fs = newFlagSet("check", "[-trace=lex,parse] <file>...")
+	var events = traceFlag(fs)
+	files, ok := parseFlags(fs, args)
+	if !ok {
+		return exitUsage
+	}
+	return each(files, func(file *token.File, r io.Reader) error {
+		_, _, err := typecheck(file, r, trace.New(os.Stderr, file, *events))
+		return err
+	})
+}
+
+func fmtCmd(args []string) int {
+	var fs = newFlagSet("fmt", "[-w] [-d] <file>...")
+	var write = fs.Bool("w", false, "write the result to the file instead of standard output")
+	var diff = fs.Bool("d", false, "print a diff of the changes instead of the result")
+	files, ok := parseFlags(fs, args)
+	if !ok {
+		return exitUsage
+	}
+	for _, name := range files {
+		if *write && name == "-" {
+			fmt.Fprintln(os.Stderr, "myc fmt: cannot use -w with standard input")
+			return exitUsage
+		}
+	}
+	return each(files, func(file *token.File, r io.Reader) error {
+		// the formatter needs the whole source
+		src, err := ioutil.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		res, err := format.Source(file, src)
+		if err != nil {
+			return err
+		}
+		if !*write && !*diff {
+			_, err = os.Stdout.Write(res)
+			return err
+		}
+		if bytes.Equal(src, res) {
+			return nil
+		}
+		if *diff {
+			d, err := unifiedDiff(file.Name(), src, res)
+			if err != nil {
+				return err
+			}
+			os.Stdout.Write(d)
+		}
+		if *write {
+			info, err := os.Stat(file.Name())
+			if err != nil {
+				return err
+			}
+			return ioutil.WriteFile(file.Name(), res, info.Mode().Perm())
+		}
+		return nil
+	})
+}
+
+// unifiedDiff returns the changes from src to res as a unified diff made by
+// the diff command.
+func unifiedDiff(name string, src, res []byte) ([]byte, error) {
+	dir, err := ioutil.TempDir("", "myc")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	var a, b = filepath.Join(dir, "orig"), filepath.Join(dir, "res")
+	if err := ioutil.WriteFile(a, src, 0600); err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(b, res, 0600); err != nil {
+		return nil, err
+	}
+	out, err := exec.Command("diff", "-u", "--label", "orig/"+name, "--label", name, a, b).Output()
+	if len(out) > 0 {
+		// diff exits with status 1 when the files differ
+		return out, nil
+	}
+	return nil, err
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+
+	"myc/ast"
+	"myc/interp"
+	"myc/lexer"
+	"myc/token"
+	"myc/types"
+)
+
+const replHelp = `Enter statements to run them, the values of expressions are printed.
+Input goes on over several lines while a brace or a then is open.
+
+	:ast <code>     print the syntax tree of code
+	:tokens <code>  print the tokens of code
+	:type <expr>    print the type of an expression without running it
+	:help           print this help
+	:quit           leave, like ctrl-D
+`
+
+// repl runs the lines it reads one after the other, the variables and
+// functions they declare are kept.
+type repl struct {
+	in   *lineEditor
+	fset *token.FileSet
+	tc   *types.TypeCheckVisitor
+	ev   *interp.ExecVisitor
+}
+
+func replCmd(args []string) int {
+	var fs = newFlagSet("repl", "[-allow=os]")
+	var allow = fs.String("allow", "", "comma separated restricted modules programs may import, os for files")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return exitUsage
+	}
+	var r = &repl{
+		in:   newLineEditor(os.Stdin, os.Stdout),
+		fset: token.NewFileSet(),
+		tc:   types.NewTypeCheckVisitor(nil),
+		ev:   interp.NewExecVisitor(nil),
+	}
+	if *allow != "" {
+		r.ev.Allow(strings.Split(*allow, ",")...)
+	}
+	if r.in.terminal {
+		fmt.Println("myc repl, :help for help")
+	}
+	for {
+		src, err := r.read()
+		switch err {
+		case nil:
+		case errInterrupt:
+			continue
+		case io.EOF:
+			return exitOK
+		default:
+			fmt.Fprintf(os.Stderr, "myc repl: %v\n", err)
+			return exitError
+		}
+		var cmd, code = src, ""
+		if i := strings.IndexAny(src, " \t\n"); i >= 0 {
+			cmd, code = src[:i], strings.TrimSpace(src[i:])
+		}
+		switch cmd {
+		case ":quit", ":q":
+			return exitOK
+		case ":help":
+			fmt.Print(replHelp)
+		case ":tokens":
+			r.tokens(code)
+		case ":ast":
+			r.ast(code)
+		case ":type":
+			r.typeOf(code)
+		default:
+			if strings.HasPrefix(cmd, ":") {
+				fmt.Fprintf(os.Stderr, "unknown command %s, :help for help\n", cmd)
+				continue
+			}
+			r.eval(src)
+		}
+	}
+}
+
+// read reads an input, more lines are read while it is incomplete.
+func (r *repl) read() (string, error) {
+	var src string
+	var prompt = "myc> "
+	for {
+		line, err := r.in.readLine(prompt)
+		if err != nil {
+			return "", err
+		}
+		src += line + "\n"
+		if !incomplete(src) {
+			return strings.TrimSpace(src), nil
+		}
+		prompt = "...  "
+	}
+}
+
+// incomplete reports whether src has open braces or parentheses or ends
+// with then or else.
+func incomplete(src string) bool {
+	if strings.HasPrefix(src, ":") {
+		var i = strings.IndexAny(src, " \t\n")
+		src = src[i+1:]
+	}
+	var l = lexer.NewLexer([]byte(src))
+	var depth int
+	var last token.TokenType
+	for {
+		var t = l.GetNextToken()
+		switch t.Type {
+		case token.TokenEOF:
+			return depth > 0 || last == token.TokenThen || last == token.TokenElse
+		case token.TokenLBrace, token.TokenLParen:
+			depth++
+		case token.TokenRBrace, token.TokenRParen:
+			depth--
+		}
+		if t.Type != token.TokenEnter {
+			last = t.Type
+		}
+	}
+}
+
+// file adds the source of an input to the file set, so that the positions
+// of all inputs are different.
+func (r *repl) file(src string) *token.File {
+	return r.fset.AddFile("<input>", len(src))
+}
+
+func (r *repl) tokens(src string) {
+	var file = r.file(src)
+	tokens, err := lex(file, strings.NewReader(src), nil)
+	if err != nil {
+		report(file, []byte(src), err)
+		return
+	}
+	for _, t := range tokens {
+		fmt.Println(t)
+	}
+}
+
+func (r *repl) ast(src string) {
+	var file = r.file(src)
+	node, err := parse(file, strings.NewReader(src), nil)
+	if err != nil {
+		report(file, []byte(src), err)
+		return
+	}
+	fmt.Println(node)
+}
+
+// typeOf prints the type of an expression, it is checked but not run.
+func (r *repl) typeOf(src string) {
+	var file = r.file(src)
+	node, err := parse(file, strings.NewReader(src), nil)
+	if err != nil {
+		report(file, []byte(src), err)
+		return
+	}
+	var list = node.(ast.ASTProject).StmtList.(ast.ASTStmt).List
+	if len(list) != 1 || !ast.IsExpr(list[0]) {
+		fmt.Fprintf(os.Stderr, "%s is not an expression\n", src)
+		return
+	}
+	if err := r.tc.Check(node); err != nil {
+		report(file, []byte(src), err)
+		return
+	}
+	switch t := r.tc.Info().TypeOf(list[0]); t {
+	case "":
+		fmt.Println("no value")
+	default:
+		fmt.Println(t)
+	}
+}
+
+// eval checks and runs an input and prints the values of an expression.
+// Ctrl-C stops it.
+func (r *repl) eval(src string) {
+	var file = r.file(src)
+	node, err := parse(file, strings.NewReader(src), nil)
+	if err == nil {
+		err = r.tc.Check(node)
+		if err != nil {
+			r.tc.Rollback()
+		}
+	}
+	if err != nil {
+		report(file, []byte(src), err)
+		return
+	}
+
+	var ctx, cancel = context.WithCancel(context.Background())
+	var sig = make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	go func() {
+		if _, ok := <-sig; ok {
+			cancel()
+		}
+	}()
+	values, err := r.ev.Eval(ctx, node.(ast.ASTProject))
+	signal.Stop(sig)
+	close(sig)
+	cancel()
+	if err != nil {
+		// the interpreter declared nothing, neither does the checker
+		r.tc.Rollback()
+	}
+	if err == context.Canceled {
+		fmt.Fprintln(os.Stderr, "interrupted")
+		return
+	}
+	if err != nil {
+		report(file, []byte(src), err)
+		return
+	}
+	var s []string
+	for _, v := range values {
+		if v, ok := v.(interp.StringValue); ok {
+			s = append(s, strconv.Quote(string(v)))
+			continue
+		}
+		s = append(s, v.String())
+	}
+	if len(s) > 0 {
+		fmt.Println(strings.Join(s, ", "))
+	}
+}
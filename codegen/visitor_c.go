@@ -0,0 +1,939 @@
+// Package codegen compiles myc programs to C or Go source.
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"myc/ast"
+	"myc/diag"
+	"myc/stdlib"
+	"myc/types"
+)
+
+// cTypes maps the myc types onto C, ints are 64 bits like in the
+// interpreter.
+var cTypes = map[string]string{
+	"int":    "long long",
+	"float":  "double",
+	"string": "const char *",
+	"bool":   "bool",
+}
+
+// cHelper is a function added to the generated program when it is used.
+type cHelper struct {
+	includes []string
+	src      string
+}
+
+var cHelpers = map[string]cHelper{
+	"_myc_concat": {
+		includes: []string{"stdlib.h", "string.h"},
+		src: `const char *_myc_concat(const char *a, const char *b) {
+char *s = malloc(strlen(a) + strlen(b) + 1);
+strcpy(s, a);
+strcat(s, b);
+return s;
+}`,
+	},
+	"_myc_sprintf": {
+		includes: []string{"stdarg.h", "stdio.h", "stdlib.h"},
+		src: `const char *_myc_sprintf(const char *format, ...) {
+va_list ap;
+va_start(ap, format);
+int n = vsnprintf(NULL, 0, format, ap);
+va_end(ap);
+char *s = malloc(n + 1);
+va_start(ap, format);
+vsnprintf(s, n + 1, format, ap);
+va_end(ap);
+return s;
+}`,
+	},
+	"_myc_len": {
+		includes: []string{"string.h"},
+		src: `long long _myc_len(const char *s) {
+return strlen(s);
+}`,
+	},
+	"_myc_contains": {
+		includes: []string{"stdbool.h", "string.h"},
+		src: `bool _myc_contains(const char *s, const char *sub) {
+return strstr(s, sub) != NULL;
+}`,
+	},
+	"_myc_index": {
+		includes: []string{"string.h"},
+		src: `long long _myc_index(const char *s, const char *sub) {
+const char *p = strstr(s, sub);
+return p ? p - s : -1;
+}`,
+	},
+	"_myc_slice": {
+		includes: []string{"stdio.h", "stdlib.h", "string.h"},
+		src: `const char *_myc_slice(const char *s, long long i, long long j) {
+long long n = strlen(s);
+if (i < 0 || j < i || j > n) {
+fprintf(stderr, "slice bounds out of range [%lld:%lld] with length %lld\n", i, j, n);
+exit(2);
+}
+char *r = malloc(j - i + 1);
+memcpy(r, s + i, j - i);
+r[j - i] = '\0';
+return r;
+}`,
+	},
+	"_myc_upper": {
+		includes: []string{"ctype.h", "stdlib.h", "string.h"},
+		src: `const char *_myc_upper(const char *s) {
+char *r = malloc(strlen(s) + 1);
+int i = 0;
+for (; s[i] != '\0'; i++) {
+r[i] = toupper((unsigned char)s[i]);
+}
+r[i] = '\0';
+return r;
+}`,
+	},
+	"_myc_lower": {
+		includes: []string{"ctype.h", "stdlib.h", "string.h"},
+		src: `const char *_myc_lower(const char *s) {
+char *r = malloc(strlen(s) + 1);
+int i = 0;
+for (; s[i] != '\0'; i++) {
+r[i] = tolower((unsigned char)s[i]);
+}
+r[i] = '\0';
+return r;
+}`,
+	},
+	"_myc_repeat": {
+		includes: []string{"stdio.h", "stdlib.h", "string.h"},
+		src: `const char *_myc_repeat(const char *s, long long n) {
+if (n < 0) {
+fprintf(stderr, "negative repeat count %lld\n", n);
+exit(2);
+}
+size_t len = strlen(s);
+char *r = malloc(len * n + 1);
+for (long long i = 0; i < n; i++) {
+memcpy(r + i * len, s, len);
+}
+r[len * n] = '\0';
+return r;
+}`,
+	},
+	"_myc_trim": {
+		includes: []string{"ctype.h", "stdlib.h", "string.h"},
+		src: `const char *_myc_trim(const char *s) {
+while (isspace((unsigned char)*s)) {
+s++;
+}
+int n = strlen(s);
+while (n > 0 && isspace((unsigned char)s[n - 1])) {
+n--;
+}
+char *r = malloc(n + 1);
+memcpy(r, s, n);
+r[n] = '\0';
+return r;
+}`,
+	},
+	"_myc_readFile": {
+		includes: []string{"stdio.h", "stdlib.h"},
+		src: `const char *_myc_readFile(const char *name) {
+FILE *f = fopen(name, "rb");
+if (f == NULL) {
+perror(name);
+exit(2);
+}
+size_t n = 0, size = 4096;
+char *s = malloc(size);
+for (size_t k; (k = fread(s + n, 1, size - n - 1, f)) > 0;) {
+n += k;
+if (n + 1 == size) {
+size *= 2;
+s = realloc(s, size);
+}
+}
+if (ferror(f)) {
+perror(name);
+exit(2);
+}
+fclose(f);
+s[n] = '\0';
+return s;
+}`,
+	},
+	"_myc_writeFile": {
+		includes: []string{"stdio.h", "stdlib.h"},
+		src: `void _myc_writeFile(const char *name, const char *data) {
+FILE *f = fopen(name, "wb");
+if (f == NULL || fputs(data, f) == EOF || fclose(f) == EOF) {
+perror(name);
+exit(2);
+}
+}`,
+	},
+}
+
+// cFunc is a C function that implements a function of a standard module,
+// a library function declared by includes or one of the helpers.
+type cFunc struct {
+	name     string
+	includes []string
+	format   bool // the first argument is a printf format
+}
+
+// cStdlib maps the functions of the standard modules onto C.
+var cStdlib = map[string]cFunc{
+	"stdio.printf":  {name: "printf", includes: []string{"stdio.h"}, format: true},
+	"stdio.puts":    {name: "puts", includes: []string{"stdio.h"}},
+	"stdio.sprintf": {name: "_myc_sprintf", format: true},
+
+	"strings.len":      {name: "_myc_len"},
+	"strings.contains": {name: "_myc_contains"},
+	"strings.index":    {name: "_myc_index"},
+	"strings.slice":    {name: "_myc_slice"},
+	"strings.upper":    {name: "_myc_upper"},
+	"strings.lower":    {name: "_myc_lower"},
+	"strings.repeat":   {name: "_myc_repeat"},
+	"strings.trim":     {name: "_myc_trim"},
+
+	"math.sqrt":  {name: "sqrt", includes: []string{"math.h"}},
+	"math.floor": {name: "floor", includes: []string{"math.h"}},
+	"math.ceil":  {name: "ceil", includes: []string{"math.h"}},
+	"math.abs":   {name: "fabs", includes: []string{"math.h"}},
+	"math.sin":   {name: "sin", includes: []string{"math.h"}},
+	"math.cos":   {name: "cos", includes: []string{"math.h"}},
+	"math.exp":   {name: "exp", includes: []string{"math.h"}},
+	"math.log":   {name: "log", includes: []string{"math.h"}},
+	"math.pow":   {name: "pow", includes: []string{"math.h"}},
+
+	"os.readFile":  {name: "_myc_readFile"},
+	"os.writeFile": {name: "_myc_writeFile"},
+}
+
+// NewExportCVisitor returns a visitor that writes ast as C source.
+// info is the result of the type checker, it may be nil.
+func NewExportCVisitor(node ast.AST, info *types.TypeInfo, w io.Writer) *ExportCVisitor {
+	return &ExportCVisitor{
+		ast:    node,
+		info:   info,
+		Writer: w,
+	}
+}
+
+type ExportCVisitor struct {
+	ast  ast.AST
+	info *types.TypeInfo
+	st   *types.Scope
+
+	names    map[*types.Object]string // C names of functions
+	imports  map[string]string        // import name -> header
+	std      map[string]string        // import name -> standard module
+	includes map[string]bool
+	helpers  map[string]bool
+	structs  []string // result types of functions with several results
+	protos   []string
+	globals  []string
+	funcs    []string
+	outer    string // name of the function being generated
+	cont     string // label continue jumps to, empty for a plain continue
+	jumped   bool   // whether cont was used
+	tmp      int
+
+	io.Writer
+}
+
+// Exec writes the program as a C translation unit. The program is type
+// checked first when no type information was given.
+func (ev *ExportCVisitor) Exec() (err error) {
+	defer diag.Catch(&err)
+	if ev.info == nil {
+		var tc = types.NewTypeCheckVisitor(ev.ast)
+		if err := tc.Exec(); err != nil {
+			return err
+		}
+		ev.info = tc.Info()
+	}
+	ev.st = types.NewScope(nil)
+	ev.names = make(map[*types.Object]string)
+	ev.imports = make(map[string]string)
+	ev.std = make(map[string]string)
+	ev.includes = make(map[string]bool)
+	ev.helpers = make(map[string]bool)
+	_, err = io.WriteString(ev, indentC(ev.exec(ev.ast)))
+	return err
+}
+
+func (ev *ExportCVisitor) errorf(node ast.AST, format string, args ...interface{}) {
+	panic(diag.Errorf(ast.PosOf(node), format, args...))
+}
+
+func (ev *ExportCVisitor) exec(node ast.AST) string {
+	switch node := node.(type) {
+	case ast.ASTProject:
+		return ev.project(node)
+	case ast.ASTStmt:
+		return "{\n" + ev.block(node) + "}"
+	case ast.ASTAssign:
+		return ev.assign(node)
+	case ast.ASTBranch:
+		var s = fmt.Sprintf("if (%s) {\n%s}", ev.cond(node.Cond), ev.body(node.Then))
+		switch f := node.Else.(type) {
+		case nil:
+		case ast.ASTBranch:
+			s += " else " + ev.exec(f)
+		default:
+			s += fmt.Sprintf(" else {\n%s}", ev.body(f))
+		}
+		return s
+	case ast.ASTWhile:
+		var body, _ = ev.loopBody(node.Body, "")
+		return fmt.Sprintf("while (%s) {\n%s}", ev.cond(node.Cond), body)
+	case ast.ASTFor:
+		return ev.loop(node)
+	case ast.ASTRange:
+		var prev = ev.st
+		ev.st = types.NewScope(prev)
+		defer func() { ev.st = prev }()
+		var key, n = ev.name(node.Key.Name), ev.tmps(1)[0]
+		var s = fmt.Sprintf("for (%s %s = %s, %s = %s; %s < %s; %s++) {\n", ev.cType(node, types.Int), key, ev.expr(node.From), n, ev.expr(node.To), key, n, key)
+		ev.st.Insert(node.Key.Name, types.Int)
+		var body, _ = ev.loopBody(node.Body, "")
+		return s + body + "}"
+	case ast.ASTBreak:
+		return "break;"
+	case ast.ASTContinue:
+		if ev.cont != "" {
+			ev.jumped = true
+			return "goto " + ev.cont + ";"
+		}
+		return "continue;"
+	case ast.ASTFunction:
+		// C has no nested functions, they are moved to the top level and
+		// only see the globals and themselves
+		var name = ev.name(node.Name.Name)
+		if ev.outer != "" {
+			name = ev.outer + "_" + node.Name.Name
+		}
+		var s = ev.declareFunc(node, name)
+		var prev = ev.st
+		for ev.st.Outer() != nil {
+			ev.st = ev.st.Outer()
+		}
+		ev.st = types.NewScope(ev.st)
+		ev.st.Add(s)
+		ev.function(node, s)
+		ev.st = prev
+		return ""
+	case ast.ASTReturn:
+		var tmp []string
+		for _, a := range node.Exprs {
+			tmp = append(tmp, ev.expr(a))
+		}
+		var s string
+		switch len(tmp) {
+		case 0:
+			s = "return;"
+		case 1:
+			s = "return " + tmp[0] + ";"
+		default:
+			s = fmt.Sprintf("return (%s_result){%s};", ev.outer, strings.Join(tmp, ", "))
+		}
+		if node.Error != "" {
+			s += fmt.Sprintf(" /*:%s*/", node.Error)
+		}
+		return s
+	case ast.ASTCallFunc:
+		return ev.expr(node) + ";"
+	case ast.ASTEmpty:
+		return ""
+	}
+	return "(void)" + ev.expr(node) + ";"
+}
+
+func (ev *ExportCVisitor) project(node ast.ASTProject) string {
+	for _, i := range node.Imports {
+		var name = path.Base(i.Path)
+		name = strings.TrimSuffix(name, path.Ext(name))
+		if m := stdlib.Lookup(i.Path); m != nil {
+			ev.std[name] = m.Name
+			continue
+		}
+		var header = i.Path
+		if path.Ext(header) != ".h" {
+			header += ".h"
+		}
+		ev.imports[name] = header
+	}
+
+	var list []ast.AST
+	if stmt, ok := node.StmtList.(ast.ASTStmt); ok {
+		list = stmt.List
+	} else {
+		list = []ast.AST{node.StmtList}
+	}
+	// functions can be called before they are declared
+	var funcs = make(map[int]*types.Object)
+	for i, a := range list {
+		if f, ok := a.(ast.ASTFunction); ok {
+			funcs[i] = ev.declareFunc(f, ev.name(f.Name.Name))
+		}
+	}
+
+	var init []string
+	for i, a := range list {
+		if f, ok := a.(ast.ASTFunction); ok {
+			ev.function(f, funcs[i])
+			continue
+		}
+		if s := ev.exec(a); s != "" {
+			init = append(init, s)
+		}
+	}
+
+	var names []string
+	for h := range ev.helpers {
+		names = append(names, h)
+		for _, inc := range cHelpers[h].includes {
+			ev.includes[inc] = true
+		}
+	}
+	sort.Strings(names)
+	var headers []string
+	for h := range ev.includes {
+		headers = append(headers, fmt.Sprintf("#include <%s>", h))
+	}
+	sort.Strings(headers)
+
+	// the parts of the program are separated by empty lines
+	var parts = []string{"// Code generated by myc. DO NOT EDIT."}
+	parts = append(parts, strings.Join(headers, "\n"))
+	parts = append(parts, ev.structs...)
+	parts = append(parts, strings.Join(ev.protos, "\n"), strings.Join(ev.globals, "\n"))
+	for _, h := range names {
+		parts = append(parts, cHelpers[h].src)
+	}
+	if len(init) > 0 {
+		parts = append(parts, fmt.Sprintf("static void _myc_init(void) {\n%s\n}", strings.Join(init, "\n")))
+	}
+	parts = append(parts, ev.funcs...)
+	parts = append(parts, ev.mainFunc(len(init) > 0))
+	var buf bytes.Buffer
+	for _, p := range parts {
+		if p != "" {
+			buf.WriteString(p + "\n\n")
+		}
+	}
+	return buf.String()
+}
+
+// mainFunc returns the C entry point, it runs the top level statements and
+// calls the myc main function if there is one.
+func (ev *ExportCVisitor) mainFunc(init bool) string {
+	var body string
+	if init {
+		body = "_myc_init();\n"
+	}
+	var s = ev.st.Lookup("main")
+	switch {
+	case s == nil || s.Sig == nil:
+		body += "return 0;\n"
+	case len(s.Sig.Results) == 1 && s.Sig.Results[0] == types.Int:
+		body += fmt.Sprintf("return (int)%s();\n", ev.names[s])
+	default:
+		body += fmt.Sprintf("%s();\nreturn 0;\n", ev.names[s])
+	}
+	return "int main(void) {\n" + body + "}"
+}
+
+// declareFunc adds the function to the current scope under its C name and
+// writes its prototype and result type.
+func (ev *ExportCVisitor) declareFunc(node ast.ASTFunction, name string) *types.Object {
+	var sig = ev.info.SignatureOf(node)
+	if sig == nil {
+		ev.errorf(node, "missing type information for %s", node.Name.Name)
+	}
+	for i, p := range node.Params {
+		if p.Type == "" {
+			inferred(p, sig.Params[i], "parameter "+p.Name)
+		}
+	}
+	if len(node.Results) == 0 {
+		for _, r := range sig.Results {
+			inferred(node.Name, r, "result of "+node.Name.Name)
+		}
+	}
+	var s = ev.st.Insert(node.Name.Name, "func")
+	s.Sig = sig
+	ev.names[s] = name
+	if len(sig.Results) > 1 {
+		var fields []string
+		for i, r := range sig.Results {
+			fields = append(fields, fmt.Sprintf("%s;", cDecl(ev.cType(node, r), fmt.Sprintf("r%d", i))))
+		}
+		ev.structs = append(ev.structs, fmt.Sprintf("typedef struct {\n%s\n} %s_result;", strings.Join(fields, "\n"), ev.names[s]))
+	}
+	ev.protos = append(ev.protos, ev.signature(node, s)+";")
+	return s
+}
+
+// result returns the C result type of a function.
+func (ev *ExportCVisitor) result(node ast.AST, s *types.Object) string {
+	switch len(s.Sig.Results) {
+	case 0:
+		return "void"
+	case 1:
+		return ev.cType(node, s.Sig.Results[0])
+	}
+	return ev.names[s] + "_result"
+}
+
+func (ev *ExportCVisitor) signature(node ast.ASTFunction, s *types.Object) string {
+	var params []string
+	for i, p := range node.Params {
+		params = append(params, cDecl(ev.cType(p, s.Sig.Params[i]), ev.name(p.Name)))
+	}
+	if len(params) == 0 {
+		params = append(params, "void")
+	}
+	return cDecl(ev.result(node, s), fmt.Sprintf("%s(%s)", ev.names[s], strings.Join(params, ", ")))
+}
+
+// function adds the definition of a function to the program.
+func (ev *ExportCVisitor) function(node ast.ASTFunction, s *types.Object) {
+	var prev, outer = ev.st, ev.outer
+	ev.st = types.NewScope(prev)
+	ev.outer = ev.names[s]
+	defer func() { ev.st, ev.outer = prev, outer }()
+	for i, p := range node.Params {
+		ev.st.Insert(p.Name, s.Sig.Params[i])
+	}
+
+	var body = ev.body(node.Body)
+	if len(s.Sig.Results) > 0 && !endsWithReturn(node.Body) {
+		// C needs a value also where myc returns nothing
+		body += fmt.Sprintf("return (%s){0};\n", ev.result(node, s))
+	}
+	ev.funcs = append(ev.funcs, fmt.Sprintf("%s {\n%s}", ev.signature(node, s), body))
+}
+
+// loop returns a C style loop. init is put in front of the loop. C only
+// allows an expression as post, when it takes several statements it is put
+// at the end of the body and continue jumps to it.
+func (ev *ExportCVisitor) loop(node ast.ASTFor) string {
+	var prev = ev.st
+	ev.st = types.NewScope(prev)
+	defer func() { ev.st = prev }()
+	var init, cond, post string
+	if node.Init != nil {
+		init = ev.exec(node.Init)
+	}
+	if node.Cond != nil {
+		cond = ev.cond(node.Cond)
+	}
+	if node.Post != nil {
+		post = ev.exec(node.Post)
+	}
+	var s string
+	if strings.Contains(post, "\n") {
+		var label = strings.Replace(ev.tmps(1)[0], "_t", "_c", 1)
+		var body, jumped = ev.loopBody(node.Body, label)
+		if jumped {
+			post = label + ":;\n" + post
+		}
+		s = fmt.Sprintf("for (; %s; ) {\n%s%s\n}", cond, body, post)
+	} else {
+		var body, _ = ev.loopBody(node.Body, "")
+		s = fmt.Sprintf("for (; %s; %s) {\n%s}", cond, strings.TrimSuffix(post, ";"), body)
+	}
+	if init == "" {
+		return s
+	}
+	return "{\n" + init + "\n" + s + "\n}"
+}
+
+// loopBody returns the body of a loop and whether continue jumps to
+// label in it. An empty label makes continue a plain continue.
+func (ev *ExportCVisitor) loopBody(node ast.AST, label string) (string, bool) {
+	var cont, jumped = ev.cont, ev.jumped
+	ev.cont, ev.jumped = label, false
+	defer func() { ev.cont, ev.jumped = cont, jumped }()
+	var s = ev.body(node)
+	return s, ev.jumped
+}
+
+// body returns the statements of a block without the surrounding braces.
+func (ev *ExportCVisitor) body(node ast.AST) string {
+	if stmt, ok := node.(ast.ASTStmt); ok {
+		return ev.block(stmt)
+	}
+	var prev = ev.st
+	ev.st = types.NewScope(prev)
+	defer func() { ev.st = prev }()
+	if s := ev.exec(node); s != "" {
+		return s + "\n"
+	}
+	return ""
+}
+
+func (ev *ExportCVisitor) block(node ast.ASTStmt) string {
+	var prev = ev.st
+	ev.st = types.NewScope(prev)
+	defer func() { ev.st = prev }()
+	var buf bytes.Buffer
+	for _, a := range node.List {
+		if s := ev.exec(a); s != "" {
+			buf.WriteString(s + "\n")
+		}
+	}
+	return buf.String()
+}
+
+func (ev *ExportCVisitor) assign(node ast.ASTAssign) string {
+	if node.IsDefined && node.Op != "=" {
+		ev.errorf(node, "unexpected %s in var declaration", node.Op)
+	}
+	var left, kinds []string
+	for _, v := range node.Left {
+		left = append(left, ev.name(v.Name))
+		if node.IsDefined {
+			var t = ev.info.TypeOf(v)
+			if v.Type == "" {
+				inferred(v, t, v.Name)
+			}
+			kinds = append(kinds, t)
+		} else {
+			kinds = append(kinds, ev.typeOf(v))
+		}
+	}
+
+	// values holds what is assigned to each variable
+	var s []string
+	var values = make([]string, len(left))
+	switch {
+	case len(node.Right) == 1 && len(left) > 1:
+		var call, ok = node.Right[0].(ast.ASTCallFunc)
+		if ok && len(ev.results(call)) == len(left) {
+			// var a, b = f() where f has as many results as there are variables
+			var tmp = ev.tmps(1)[0]
+			s = append(s, fmt.Sprintf("%s %s = %s;", ev.result(call, ev.st.Lookup(call.Name.Name)), tmp, ev.expr(call)))
+			for i := range values {
+				values[i] = fmt.Sprintf("%s.r%d", tmp, i)
+			}
+			break
+		}
+		// exp. var a,b,c=1
+		var tmp = ev.tmps(1)[0]
+		s = append(s, cDecl(ev.cType(node.Right[0], ev.typeOf(node.Right[0])), tmp)+" = "+ev.expr(node.Right[0])+";")
+		for i := range values {
+			values[i] = tmp
+		}
+	case len(node.Right) != len(left):
+		ev.errorf(node, "assignment mismatch: %d variables but %d values", len(node.Left), len(node.Right))
+	case len(left) == 1:
+		values[0] = ev.expr(node.Right[0])
+		if node.IsDefined && ev.st.Outer() != nil {
+			// a single local declaration is initialized directly
+			ev.st.Insert(node.Left[0].Name, kinds[0])
+			return fmt.Sprintf("%s = %s;\n(void)%s;", cDecl(ev.cType(node, kinds[0]), left[0]), values[0], left[0])
+		}
+	default:
+		// all values are computed before they are assigned
+		var tmps = ev.tmps(len(left))
+		for i, a := range node.Right {
+			s = append(s, cDecl(ev.cType(a, ev.typeOf(a)), tmps[i])+" = "+ev.expr(a)+";")
+			values[i] = tmps[i]
+		}
+	}
+
+	for i := range left {
+		var v = values[i]
+		if node.Op != "=" && kinds[i] == types.String {
+			if node.Op != "+=" {
+				ev.errorf(node, "invalid operation: operator %s not defined on string", node.Op)
+			}
+			ev.helpers["_myc_concat"] = true
+			s = append(s, fmt.Sprintf("%s = _myc_concat(%s, %s);", left[i], left[i], v))
+			continue
+		}
+		s = append(s, fmt.Sprintf("%s %s %s;", left[i], node.Op, v))
+	}
+	if !node.IsDefined {
+		return strings.Join(s, "\n")
+	}
+
+	var decl []string
+	for i, v := range node.Left {
+		if ev.st.Outer() == nil {
+			if ev.st.Local(v.Name) == nil {
+				ev.globals = append(ev.globals, cDecl(ev.cType(v, kinds[i]), left[i])+";")
+			}
+		} else {
+			decl = append(decl, cDecl(ev.cType(v, kinds[i]), left[i])+";")
+			s = append(s, "(void)"+left[i]+";")
+		}
+		ev.st.Insert(v.Name, kinds[i])
+	}
+	return strings.Join(append(decl, s...), "\n")
+}
+
+func (ev *ExportCVisitor) tmps(n int) []string {
+	var list []string
+	for i := 0; i < n; i++ {
+		ev.tmp++
+		list = append(list, fmt.Sprintf("_t%d", ev.tmp))
+	}
+	return list
+}
+
+// results returns the result types of a call.
+func (ev *ExportCVisitor) results(node ast.ASTCallFunc) []string {
+	if s := ev.st.Lookup(node.Name.Name); s != nil && s.Sig != nil {
+		return s.Sig.Results
+	}
+	return nil
+}
+
+// typeOf returns the myc type of an expression.
+func (ev *ExportCVisitor) typeOf(node ast.AST) string {
+	if t := ev.info.TypeOf(node); t != "" {
+		return t
+	}
+	if v, ok := node.(ast.ASTVariable); ok {
+		if s := ev.st.Lookup(v.Name); s != nil {
+			return s.Type
+		}
+	}
+	return types.Any
+}
+
+// cType returns the C type of a myc type.
+func (ev *ExportCVisitor) cType(node ast.AST, t string) string {
+	if c, ok := cTypes[t]; ok {
+		if t == types.Bool {
+			ev.includes["stdbool.h"] = true
+		}
+		return c
+	}
+	ev.errorf(node, "cannot use %s values in C", t)
+	return ""
+}
+
+// cDecl declares name as a t.
+func cDecl(t, name string) string {
+	if strings.HasSuffix(t, "*") {
+		return t + name
+	}
+	return t + " " + name
+}
+
+// cond returns ast as a C condition.
+func (ev *ExportCVisitor) cond(node ast.AST) string {
+	if ev.typeOf(node) == types.String {
+		return fmt.Sprintf("(%s)[0] != '\\0'", ev.expr(node))
+	}
+	return ev.expr(node)
+}
+
+func (ev *ExportCVisitor) expr(node ast.AST) string {
+	switch node := node.(type) {
+	case ast.ASTNumber:
+		// C has no binary or octal prefixes
+		if node.Kind == types.Int && len(node.Lit) > 1 && strings.ContainsRune("bBoO", rune(node.Lit[1])) {
+			return strconv.FormatInt(node.Int, 10) + "LL"
+		}
+		if node.Kind == types.Int {
+			// a plain constant is a C int and 1 << 40 would overflow
+			return node.Literal() + "LL"
+		}
+		return node.Literal()
+	case ast.ASTString:
+		return cQuote(node.Value)
+	case ast.ASTBool:
+		ev.includes["stdbool.h"] = true
+		return strconv.FormatBool(node.Value)
+	case ast.ASTVariable:
+		var s = ev.st.Lookup(node.Name)
+		if s == nil && !strings.Contains(node.Name, ".") {
+			ev.errorf(node, "cannot use %s in a nested function in C", node.Name)
+		}
+		if s != nil && s.Sig != nil {
+			ev.errorf(node, "cannot use function %s as a value in C", node.Name)
+		}
+		return ev.name(node.Name)
+	case ast.ASTUnaryOp:
+		if node.Op == "!" {
+			return "(!" + ev.cond(node.Operand) + ")"
+		}
+		return "(" + node.Op + ev.expr(node.Operand) + ")"
+	case ast.ASTBinaryOp:
+		var op = node.Op
+		switch op {
+		case "&&", "||":
+			return fmt.Sprintf("(%s %s %s)", ev.cond(node.Left), op, ev.cond(node.Right))
+		case "as":
+			if t, ok := node.Right.(ast.ASTVariable); ok {
+				return fmt.Sprintf("((%s)%s)", ev.cType(t, t.Name), ev.expr(node.Left))
+			}
+			ev.errorf(node.Right, "cannot convert to %s", ast.ExprString(node.Right))
+		}
+		return ev.binary(node, op, node.Left, node.Right)
+	case ast.ASTCallFunc:
+		var tmp []string
+		var f, _ = ev.stdFunc(node.Name.Name)
+		var format *stdlib.Format
+		for i, a := range node.Args {
+			switch {
+			case f.format && i == 0:
+				format = printfFormat(a)
+				tmp = append(tmp, cQuote(format.C))
+			case format != nil && i <= len(format.Args):
+				tmp = append(tmp, ev.formatArg(a, format.Args[i-1]))
+			default:
+				tmp = append(tmp, ev.expr(a))
+			}
+		}
+		var name = ev.name(node.Name.Name)
+		if s := ev.st.Lookup(node.Name.Name); s != nil && s.Sig != nil {
+			name = ev.names[s]
+		}
+		return fmt.Sprintf("%s(%s)", name, strings.Join(tmp, ", "))
+	}
+	ev.errorf(node, "cannot generate %s", ast.ExprString(node))
+	return ""
+}
+
+// formatArg returns an argument of a printf format with the C type the
+// format reads. Widths, precisions and %c read an int, the other int
+// conversions a long long, which bools are not.
+func (ev *ExportCVisitor) formatArg(node ast.AST, a stdlib.FormatArg) string {
+	switch {
+	case a.Type != types.Int:
+		return ev.expr(node)
+	case a.Star || strings.HasSuffix(a.Conv, "c"):
+		return "(int)" + ev.expr(node)
+	case ev.typeOf(node) != types.Int:
+		return "(long long)" + ev.expr(node)
+	}
+	return ev.expr(node)
+}
+
+// binary returns l op r, strings are concatenated and compared by helpers.
+func (ev *ExportCVisitor) binary(node ast.AST, op string, l, r ast.AST) string {
+	var left, right = ev.expr(l), ev.expr(r)
+	if ev.typeOf(l) != types.String || ev.typeOf(r) != types.String {
+		return fmt.Sprintf("(%s %s %s)", left, op, right)
+	}
+	switch op {
+	case "+":
+		ev.helpers["_myc_concat"] = true
+		return fmt.Sprintf("_myc_concat(%s, %s)", left, right)
+	case "==", "!=", "<", "<=", ">", ">=":
+		ev.includes["string.h"] = true
+		return fmt.Sprintf("(strcmp(%s, %s) %s 0)", left, right, op)
+	}
+	ev.errorf(node, "invalid operation: operator %s not defined on string", op)
+	return ""
+}
+
+// name returns the C name of a variable. The names of the program get a
+// myc_ prefix, so that they collide neither with C keywords and the C
+// library nor with the names of the generated code. Imported names are
+// plain C symbols declared by the imported header or the C functions of a
+// standard module.
+func (ev *ExportCVisitor) name(name string) string {
+	var i = strings.Index(name, ".")
+	if i < 0 {
+		return "myc_" + name
+	}
+	if f, ok := ev.stdFunc(name); ok {
+		for _, inc := range f.includes {
+			ev.includes[inc] = true
+		}
+		if _, ok := cHelpers[f.name]; ok {
+			ev.helpers[f.name] = true
+		}
+		return f.name
+	}
+	if header, ok := ev.imports[name[:i]]; ok {
+		ev.includes[header] = true
+		return name[i+1:]
+	}
+	return "myc_" + strings.Replace(name, ".", "_", -1)
+}
+
+// stdFunc returns the C function of a name qualified by a standard module.
+func (ev *ExportCVisitor) stdFunc(name string) (cFunc, bool) {
+	var i = strings.Index(name, ".")
+	if i < 0 {
+		return cFunc{}, false
+	}
+	m, ok := ev.std[name[:i]]
+	if !ok {
+		return cFunc{}, false
+	}
+	f, ok := cStdlib[m+name[i:]]
+	return f, ok
+}
+
+// cQuote returns s as a C string literal.
+func cQuote(s string) string {
+	var buf bytes.Buffer
+	buf.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"', '\\':
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\t':
+			buf.WriteString(`\t`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '?':
+			// keep "??" from starting a trigraph
+			if i > 0 && s[i-1] == '?' {
+				buf.WriteByte('\\')
+			}
+			buf.WriteByte(c)
+		default:
+			if c < ' ' || c >= 0x7f {
+				// octal escapes take at most three digits, unlike \x
+				fmt.Fprintf(&buf, "\\%03o", c)
+				continue
+			}
+			buf.WriteByte(c)
+		}
+	}
+	buf.WriteByte('"')
+	return buf.String()
+}
+
+// indentC indents the lines of src by the depth of the braces.
+func indentC(src string) string {
+	var buf bytes.Buffer
+	var depth int
+	for _, line := range strings.Split(src, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "}") && depth > 0 {
+			depth--
+		}
+		if line != "" {
+			buf.WriteString(strings.Repeat("\t", depth))
+		}
+		buf.WriteString(line + "\n")
+		if strings.HasSuffix(line, "{") {
+			depth++
+		}
+	}
+	return strings.TrimRight(buf.String(), "\n") + "\n"
+}
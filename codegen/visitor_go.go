@@ -0,0 +1,710 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"myc/ast"
+	"myc/diag"
+	"myc/stdlib"
+	"myc/types"
+)
+
+// goFunc is a Go function that implements a function of a standard
+// module, qualified by its package, a builtin or one of the helpers.
+type goFunc struct {
+	name    string
+	imports []string
+
+	// format is set when the first argument is a printf format of C, it is
+	// translated to Go and bools are passed as ints like in C
+	format bool
+}
+
+// goStdlib maps the functions of the standard modules onto Go. Imports that
+// are not standard modules are passed through as Go import paths.
+var goStdlib = map[string]goFunc{
+	"stdio.printf":  {name: "fmt.Printf", imports: []string{"fmt"}, format: true},
+	"stdio.puts":    {name: "fmt.Println", imports: []string{"fmt"}},
+	"stdio.sprintf": {name: "fmt.Sprintf", imports: []string{"fmt"}, format: true},
+
+	"strings.len":      {name: "len"},
+	"strings.contains": {name: "strings.Contains", imports: []string{"strings"}},
+	"strings.index":    {name: "strings.Index", imports: []string{"strings"}},
+	"strings.slice":    {name: "_myc_slice"},
+	"strings.upper":    {name: "strings.ToUpper", imports: []string{"strings"}},
+	"strings.lower":    {name: "strings.ToLower", imports: []string{"strings"}},
+	"strings.repeat":   {name: "strings.Repeat", imports: []string{"strings"}},
+	"strings.trim":     {name: "strings.TrimSpace", imports: []string{"strings"}},
+
+	"math.sqrt":  {name: "math.Sqrt", imports: []string{"math"}},
+	"math.floor": {name: "math.Floor", imports: []string{"math"}},
+	"math.ceil":  {name: "math.Ceil", imports: []string{"math"}},
+	"math.abs":   {name: "math.Abs", imports: []string{"math"}},
+	"math.sin":   {name: "math.Sin", imports: []string{"math"}},
+	"math.cos":   {name: "math.Cos", imports: []string{"math"}},
+	"math.exp":   {name: "math.Exp", imports: []string{"math"}},
+	"math.log":   {name: "math.Log", imports: []string{"math"}},
+	"math.pow":   {name: "math.Pow", imports: []string{"math"}},
+
+	"os.readFile":  {name: "_myc_readFile", imports: []string{"io/ioutil"}},
+	"os.writeFile": {name: "_myc_writeFile", imports: []string{"io/ioutil"}},
+}
+
+var goTypes = map[string]string{
+	"int":    "int",
+	"float":  "float64",
+	"string": "string",
+	"bool":   "bool",
+	"any":    "interface{}",
+}
+
+var goHelpers = map[string]string{
+	"_myc_b2i":   "func _myc_b2i(b bool) int {\nif b {\nreturn 1\n}\nreturn 0\n}",
+	"_myc_slice": "func _myc_slice(s string, i, j int) string {\nreturn s[i:j]\n}",
+	"_myc_readFile": `func _myc_readFile(name string) string {
+b, err := ioutil.ReadFile(name)
+if err != nil {
+panic(err)
+}
+return string(b)
+}`,
+	"_myc_writeFile": `func _myc_writeFile(name, data string) {
+if err := ioutil.WriteFile(name, []byte(data), 0666); err != nil {
+panic(err)
+}
+}`,
+}
+
+// NewExportGoVisitor returns a visitor that writes ast as Go source.
+// info is the result of the type checker, it may be nil.
+func NewExportGoVisitor(node ast.AST, info *types.TypeInfo, w io.Writer) *ExportGoVisitor {
+	return &ExportGoVisitor{
+		ast:    node,
+		info:   info,
+		Writer: w,
+	}
+}
+
+type ExportGoVisitor struct {
+	ast  ast.AST
+	info *types.TypeInfo
+	st   *types.Scope
+
+	funcs   map[string][]string // function name -> result types
+	params  map[string][]string // function name -> parameter types
+	imports map[string]string   // import name -> myc import path
+	std     map[string]string   // import name -> standard module
+	used    map[string]bool     // go import paths in use
+	helpers map[string]bool
+	globals []string // package level declarations
+	tmp     int
+
+	io.Writer
+}
+
+// Exec writes the program as a gofmt'ed Go source file. The program is
+// type checked first when no type information was given.
+func (ev *ExportGoVisitor) Exec() (err error) {
+	defer diag.Catch(&err)
+	if ev.info == nil {
+		var tc = types.NewTypeCheckVisitor(ev.ast)
+		if err := tc.Exec(); err != nil {
+			return err
+		}
+		ev.info = tc.Info()
+	}
+	ev.st = types.NewScope(nil)
+	ev.funcs = make(map[string][]string)
+	ev.params = make(map[string][]string)
+	ev.imports = make(map[string]string)
+	ev.std = make(map[string]string)
+	ev.used = make(map[string]bool)
+	ev.helpers = make(map[string]bool)
+	var src = []byte(ev.exec(ev.ast))
+	// a program the formatter rejects is a bug of the backend, nothing is
+	// written then
+	out, err := format.Source(src)
+	if err != nil {
+		return fmt.Errorf("generated invalid Go source: %v", err)
+	}
+	_, err = ev.Write(out)
+	return err
+}
+
+func (ev *ExportGoVisitor) errorf(node ast.AST, format string, args ...interface{}) {
+	panic(diag.Errorf(ast.PosOf(node), format, args...))
+}
+
+func (ev *ExportGoVisitor) exec(node ast.AST) string {
+	switch node := node.(type) {
+	case ast.ASTProject:
+		return ev.project(node)
+	case ast.ASTStmt:
+		return "{\n" + ev.block(node) + "}"
+	case ast.ASTAssign:
+		return ev.assign(node)
+	case ast.ASTBranch:
+		var s = fmt.Sprintf("if %s {\n%s}", ev.cond(node.Cond), ev.body(node.Then))
+		switch f := node.Else.(type) {
+		case nil:
+		case ast.ASTBranch:
+			s += " else " + ev.exec(f)
+		default:
+			s += fmt.Sprintf(" else {\n%s}", ev.body(f))
+		}
+		return s
+	case ast.ASTWhile:
+		return fmt.Sprintf("for %s {\n%s}", ev.cond(node.Cond), ev.body(node.Body))
+	case ast.ASTFor:
+		return ev.loop(node)
+	case ast.ASTRange:
+		var prev = ev.st
+		ev.st = types.NewScope(prev)
+		defer func() { ev.st = prev }()
+		var key, n = ev.name(node.Key.Name), ev.tmps(1)[0]
+		var s = fmt.Sprintf("for %s, %s := %s, %s; %s < %s; %s++ {\n", key, n, ev.expr(node.From), ev.expr(node.To), key, n, key)
+		ev.st.Insert(node.Key.Name, "int")
+		return s + ev.body(node.Body) + "}"
+	case ast.ASTBreak:
+		return "break"
+	case ast.ASTContinue:
+		return "continue"
+	case ast.ASTFunction:
+		var name = ev.name(node.Name.Name)
+		ev.params[node.Name.Name] = ev.paramTypes(node)
+		ev.funcs[node.Name.Name] = ev.results(node)
+		ev.st.Insert(node.Name.Name, "func")
+		return fmt.Sprintf("var %s func%s\n%s = func%s\n_ = %s", name, ev.signature(node), name, ev.function(node), name)
+	case ast.ASTReturn:
+		var tmp []string
+		for _, a := range node.Exprs {
+			tmp = append(tmp, ev.expr(a))
+		}
+		if node.Error != "" {
+			return fmt.Sprintf("return %s /*:%s*/", strings.Join(tmp, ", "), node.Error)
+		}
+		return "return " + strings.Join(tmp, ", ")
+	case ast.ASTCallFunc:
+		return ev.expr(node)
+	case ast.ASTEmpty:
+		return ""
+	}
+	return "_ = " + ev.expr(node)
+}
+
+func (ev *ExportGoVisitor) project(node ast.ASTProject) string {
+	for _, i := range node.Imports {
+		var name = path.Base(i.Path)
+		name = strings.TrimSuffix(name, path.Ext(name))
+		if m := stdlib.Lookup(i.Path); m != nil {
+			// the types of the functions convert their arguments
+			ev.std[name] = m.Name
+			for fn, f := range m.Funcs {
+				ev.funcs[name+"."+fn] = goTypeList(f.Results)
+				ev.params[name+"."+fn] = goTypeList(f.Params)
+			}
+			continue
+		}
+		ev.imports[name] = i.Path
+	}
+
+	var list []ast.AST
+	if stmt, ok := node.StmtList.(ast.ASTStmt); ok {
+		list = stmt.List
+	} else {
+		list = []ast.AST{node.StmtList}
+	}
+	for _, a := range list {
+		if f, ok := a.(ast.ASTFunction); ok {
+			ev.params[f.Name.Name] = ev.paramTypes(f)
+			ev.funcs[f.Name.Name] = ev.results(f)
+		}
+	}
+
+	var funcs, init []string
+	for _, a := range list {
+		if f, ok := a.(ast.ASTFunction); ok {
+			ev.st.Insert(f.Name.Name, "func")
+			funcs = append(funcs, fmt.Sprintf("func %s%s", ev.name(f.Name.Name), ev.function(f)))
+			continue
+		}
+		if s := ev.exec(a); s != "" {
+			init = append(init, s)
+		}
+	}
+
+	var buf bytes.Buffer
+	ev.mainFunc(&funcs)
+
+	buf.WriteString("// Code generated by myc. DO NOT EDIT.\n\npackage main\n\n")
+	var paths []string
+	for p := range ev.used {
+		paths = append(paths, strconv.Quote(p))
+	}
+	sort.Strings(paths)
+	if len(paths) > 0 {
+		fmt.Fprintf(&buf, "import (\n%s\n)\n\n", strings.Join(paths, "\n"))
+	}
+	for _, g := range ev.globals {
+		buf.WriteString(g + "\n")
+	}
+	var names []string
+	for h := range ev.helpers {
+		names = append(names, h)
+	}
+	sort.Strings(names)
+	for _, h := range names {
+		buf.WriteString("\n" + goHelpers[h] + "\n")
+	}
+	if len(init) > 0 {
+		fmt.Fprintf(&buf, "\nfunc init() {\n%s\n}\n", strings.Join(init, "\n"))
+	}
+	for _, f := range funcs {
+		buf.WriteString("\n" + f + "\n")
+	}
+	return buf.String()
+}
+
+// mainFunc adds the Go entry point, calling the myc main function if there is one.
+func (ev *ExportGoVisitor) mainFunc(funcs *[]string) {
+	results, ok := ev.funcs["main"]
+	switch {
+	case !ok:
+		*funcs = append(*funcs, "func main() {}")
+	case len(results) == 1 && results[0] == "int":
+		ev.used["os"] = true
+		*funcs = append(*funcs, fmt.Sprintf("func main() {\nos.Exit(%s())\n}", ev.name("main")))
+	default:
+		*funcs = append(*funcs, fmt.Sprintf("func main() {\n%s()\n}", ev.name("main")))
+	}
+}
+
+// sig returns the signature the type checker found for a function.
+func (ev *ExportGoVisitor) sig(node ast.ASTFunction) *types.Signature {
+	var sig = ev.info.SignatureOf(node)
+	if sig == nil {
+		ev.errorf(node, "missing type information for %s", node.Name.Name)
+	}
+	return sig
+}
+
+// results returns the Go result types of a function.
+func (ev *ExportGoVisitor) results(node ast.ASTFunction) []string {
+	var list []string
+	for _, r := range ev.sig(node).Results {
+		if len(node.Results) == 0 {
+			inferred(node.Name, r, "result of "+node.Name.Name)
+		}
+		list = append(list, goType(r))
+	}
+	return list
+}
+
+func (ev *ExportGoVisitor) paramTypes(node ast.ASTFunction) []string {
+	var list []string
+	for i := range node.Params {
+		list = append(list, goType(ev.paramType(node, i)))
+	}
+	return list
+}
+
+// paramType returns the type of the i'th parameter of a function.
+func (ev *ExportGoVisitor) paramType(node ast.ASTFunction, i int) string {
+	var p, t = node.Params[i], ev.sig(node).Params[i]
+	if p.Type == "" {
+		inferred(p, t, "parameter "+p.Name)
+	}
+	return t
+}
+
+// inferred returns the type the checker inferred for what is declared at
+// node. It stops with a diagnostic when the type is not known, the backends
+// do not guess it.
+func inferred(node ast.AST, t, what string) string {
+	if t == "" || t == types.Any {
+		panic(diag.Errorf(ast.PosOf(node), "cannot infer type of %s; add a type annotation", what))
+	}
+	return t
+}
+
+// loop returns a C style loop. Go allows only simple statements in the
+// header, so init is put in front of the loop and post is wrapped in a
+// function literal when it takes several statements.
+func (ev *ExportGoVisitor) loop(node ast.ASTFor) string {
+	var prev = ev.st
+	ev.st = types.NewScope(prev)
+	defer func() { ev.st = prev }()
+	var init, cond, post string
+	if node.Init != nil {
+		init = ev.exec(node.Init)
+	}
+	if node.Cond != nil {
+		cond = ev.cond(node.Cond)
+	}
+	if node.Post != nil {
+		post = ev.exec(node.Post)
+		if strings.Contains(post, "\n") {
+			post = "func() {\n" + post + "\n}()"
+		}
+	}
+	var s = fmt.Sprintf("for ; %s; %s {\n%s}", cond, post, ev.body(node.Body))
+	if init == "" {
+		return s
+	}
+	return "{\n" + init + "\n" + s + "\n}"
+}
+
+func (ev *ExportGoVisitor) signature(node ast.ASTFunction) string {
+	var params []string
+	for i, p := range node.Params {
+		params = append(params, ev.name(p.Name)+" "+goType(ev.paramType(node, i)))
+	}
+	var results = ev.funcs[node.Name.Name]
+	switch len(results) {
+	case 0:
+		return fmt.Sprintf("(%s)", strings.Join(params, ", "))
+	case 1:
+		return fmt.Sprintf("(%s) %s", strings.Join(params, ", "), results[0])
+	}
+	return fmt.Sprintf("(%s) (%s)", strings.Join(params, ", "), strings.Join(results, ", "))
+}
+
+// function returns the signature and body of a function.
+func (ev *ExportGoVisitor) function(node ast.ASTFunction) string {
+	var prev = ev.st
+	ev.st = types.NewScope(prev)
+	defer func() { ev.st = prev }()
+	for i, p := range node.Params {
+		ev.st.Insert(p.Name, ev.paramType(node, i))
+	}
+
+	var body = ev.body(node.Body)
+	var results = ev.funcs[node.Name.Name]
+	if len(results) > 0 && !endsWithReturn(node.Body) {
+		var zero []string
+		for _, r := range results {
+			zero = append(zero, goZero(r))
+		}
+		body += "return " + strings.Join(zero, ", ") + "\n"
+	}
+	return fmt.Sprintf("%s {\n%s}", ev.signature(node), body)
+}
+
+func endsWithReturn(node ast.AST) bool {
+	switch node := node.(type) {
+	case ast.ASTReturn:
+		return true
+	case ast.ASTStmt:
+		for i := len(node.List) - 1; i >= 0; i-- {
+			if _, ok := node.List[i].(ast.ASTEmpty); ok {
+				continue
+			}
+			return endsWithReturn(node.List[i])
+		}
+	}
+	return false
+}
+
+// body returns the statements of a block without the surrounding braces.
+func (ev *ExportGoVisitor) body(node ast.AST) string {
+	if stmt, ok := node.(ast.ASTStmt); ok {
+		return ev.block(stmt)
+	}
+	var prev = ev.st
+	ev.st = types.NewScope(prev)
+	defer func() { ev.st = prev }()
+	if s := ev.exec(node); s != "" {
+		return s + "\n"
+	}
+	return ""
+}
+
+func (ev *ExportGoVisitor) block(node ast.ASTStmt) string {
+	var prev = ev.st
+	ev.st = types.NewScope(prev)
+	defer func() { ev.st = prev }()
+	var buf bytes.Buffer
+	for _, a := range node.List {
+		if s := ev.exec(a); s != "" {
+			buf.WriteString(s + "\n")
+		}
+	}
+	return buf.String()
+}
+
+func (ev *ExportGoVisitor) assign(node ast.ASTAssign) string {
+	if node.IsDefined && node.Op != "=" {
+		ev.errorf(node, "unexpected %s in var declaration", node.Op)
+	}
+	var left []string
+	for _, v := range node.Left {
+		left = append(left, ev.name(v.Name))
+	}
+	var right []string
+	for i, a := range node.Right {
+		var v = ev.expr(a)
+		if node.IsDefined && i < len(node.Left) && node.Left[i].Type != "" {
+			v = ev.convert(a, v, goType(node.Left[i].Type))
+		}
+		right = append(right, v)
+	}
+
+	// var a, b = f() where f has as many results as there are variables
+	if len(right) == 1 && len(left) > 1 {
+		if call, ok := node.Right[0].(ast.ASTCallFunc); ok && len(ev.funcs[call.Name.Name]) == len(left) {
+			if node.Op == "=" {
+				return ev.define(node, left, strings.Join(left, ", ")+" = "+right[0])
+			}
+			var tmps = ev.tmps(len(left))
+			var s = strings.Join(tmps, ", ") + " := " + right[0]
+			for i := range left {
+				s += fmt.Sprintf("\n%s %s %s", left[i], node.Op, tmps[i])
+			}
+			return s
+		}
+	}
+
+	// exp. var a,b,c=1
+	if len(right) == 1 && len(left) > 1 {
+		var tmp = ev.tmps(1)[0]
+		var s = tmp + " := " + right[0]
+		for i := range left {
+			s += fmt.Sprintf("\n%s %s %s", left[i], node.Op, tmp)
+		}
+		return ev.define(node, left, s)
+	}
+	if len(left) != len(right) {
+		ev.errorf(node, "assignment mismatch: %d variables but %d values", len(node.Left), len(right))
+	}
+	if node.Op == "=" || len(left) == 1 {
+		return ev.define(node, left, fmt.Sprintf("%s %s %s", strings.Join(left, ", "), node.Op, strings.Join(right, ", ")))
+	}
+	var tmps = ev.tmps(len(left))
+	var s = strings.Join(tmps, ", ") + " := " + strings.Join(right, ", ")
+	for i := range left {
+		s += fmt.Sprintf("\n%s %s %s", left[i], node.Op, tmps[i])
+	}
+	return s
+}
+
+// define records the types of newly declared variables and declares them
+// in front of the assignment. Top level declarations become package
+// variables so that functions can refer to them.
+func (ev *ExportGoVisitor) define(node ast.ASTAssign, left []string, s string) string {
+	if !node.IsDefined {
+		return s
+	}
+	var decl []string
+	for i, v := range node.Left {
+		var t = ev.info.TypeOf(v)
+		if v.Type == "" {
+			inferred(v, t, v.Name)
+		}
+		if ev.st.Outer() == nil {
+			if ev.st.Local(v.Name) == nil {
+				ev.globals = append(ev.globals, fmt.Sprintf("var %s %s", left[i], goType(t)))
+			}
+		} else {
+			decl = append(decl, fmt.Sprintf("var %s %s", left[i], goType(t)))
+		}
+		ev.st.Insert(v.Name, t)
+	}
+	if len(decl) == 0 {
+		return s
+	}
+	var used []string
+	for _, l := range left {
+		used = append(used, "_ = "+l)
+	}
+	return strings.Join(decl, "\n") + "\n" + s + "\n" + strings.Join(used, "\n")
+}
+
+func (ev *ExportGoVisitor) tmps(n int) []string {
+	var list []string
+	for i := 0; i < n; i++ {
+		ev.tmp++
+		list = append(list, fmt.Sprintf("_t%d", ev.tmp))
+	}
+	return list
+}
+
+// cond returns ast as a Go boolean expression.
+func (ev *ExportGoVisitor) cond(node ast.AST) string {
+	switch ev.kind(node) {
+	case "bool":
+		return ev.expr(node)
+	case "string":
+		return fmt.Sprintf("(%s != \"\")", ev.expr(node))
+	}
+	return fmt.Sprintf("(%s != 0)", ev.expr(node))
+}
+
+func (ev *ExportGoVisitor) expr(node ast.AST) string {
+	switch node := node.(type) {
+	case ast.ASTNumber:
+		return node.Literal()
+	case ast.ASTString:
+		return strconv.Quote(node.Value)
+	case ast.ASTBool:
+		return strconv.FormatBool(node.Value)
+	case ast.ASTVariable:
+		return ev.name(node.Name)
+	case ast.ASTUnaryOp:
+		switch node.Op {
+		case "-":
+			return "(-" + ev.expr(node.Operand) + ")"
+		case "~":
+			return "(^" + ev.expr(node.Operand) + ")"
+		case "!":
+			return "(!" + ev.cond(node.Operand) + ")"
+		}
+		return "(" + node.Op + ev.expr(node.Operand) + ")"
+	case ast.ASTBinaryOp:
+		switch node.Op {
+		case "&&", "||":
+			return fmt.Sprintf("(%s %s %s)", ev.cond(node.Left), node.Op, ev.cond(node.Right))
+		case "as":
+			if t, ok := node.Right.(ast.ASTVariable); ok {
+				if ev.kind(node.Left) == "bool" && t.Name != types.Bool {
+					// Go cannot convert bools, they become 0 and 1
+					ev.helpers["_myc_b2i"] = true
+					return fmt.Sprintf("%s(_myc_b2i(%s))", goType(t.Name), ev.expr(node.Left))
+				}
+				return fmt.Sprintf("%s(%s)", goType(t.Name), ev.expr(node.Left))
+			}
+			ev.errorf(node.Right, "cannot convert to %s", ast.ExprString(node.Right))
+		}
+		// ints mixed with floats are converted
+		var left = ev.convert(node.Left, ev.expr(node.Left), ev.kind(node.Right))
+		var right = ev.convert(node.Right, ev.expr(node.Right), ev.kind(node.Left))
+		return fmt.Sprintf("(%s %s %s)", left, node.Op, right)
+	case ast.ASTCallFunc:
+		var tmp []string
+		var params = ev.params[node.Name.Name]
+		var f, _ = ev.stdFunc(node.Name.Name)
+		for i, a := range node.Args {
+			var v = ev.expr(a)
+			switch {
+			case f.format && i == 0:
+				v = strconv.Quote(printfFormat(a).Go)
+			case f.format && ev.kind(a) == "bool":
+				ev.helpers["_myc_b2i"] = true
+				v = "_myc_b2i(" + v + ")"
+			case i < len(params):
+				v = ev.convert(a, v, params[i])
+			}
+			tmp = append(tmp, v)
+		}
+		return fmt.Sprintf("%s(%s)", ev.name(node.Name.Name), strings.Join(tmp, ", "))
+	}
+	ev.errorf(node, "cannot generate %s", ast.ExprString(node))
+	return ""
+}
+
+// printfFormat parses the printf format of a call to a standard function, the
+// type checker made sure that it is a string literal.
+func printfFormat(node ast.AST) *stdlib.Format {
+	s, ok := node.(ast.ASTString)
+	if !ok {
+		panic(diag.Errorf(ast.PosOf(node), "format must be a string literal"))
+	}
+	f, err := stdlib.ParseFormat(s.Value)
+	if err != nil {
+		panic(diag.Errorf(ast.PosOf(node), "%v", err))
+	}
+	return f
+}
+
+// convert converts the value s of ast to a float64 when an int is used as
+// a float. Constants need no conversion.
+func (ev *ExportGoVisitor) convert(node ast.AST, s, to string) string {
+	if _, ok := node.(ast.ASTNumber); !ok && to == "float64" && ev.kind(node) == "int" {
+		return "float64(" + s + ")"
+	}
+	return s
+}
+
+// kind returns the Go type of an expression.
+func (ev *ExportGoVisitor) kind(node ast.AST) string {
+	if t := ev.info.TypeOf(node); t != "" {
+		return goType(t)
+	}
+	return goType(types.Any)
+}
+
+// name maps a myc name to a Go identifier, resolving imported names. The
+// names of the program get a myc_ prefix, so that they collide neither with
+// Go keywords, builtins and packages nor with the names of the generated
+// code.
+func (ev *ExportGoVisitor) name(name string) string {
+	var i = strings.Index(name, ".")
+	if i < 0 {
+		return "myc_" + name
+	}
+	if f, ok := ev.stdFunc(name); ok {
+		for _, p := range f.imports {
+			ev.used[p] = true
+		}
+		if _, ok := goHelpers[f.name]; ok {
+			ev.helpers[f.name] = true
+		}
+		return f.name
+	}
+	var pkg, fn = name[:i], name[i+1:]
+	p, ok := ev.imports[pkg]
+	if !ok {
+		return name
+	}
+	ev.used[p] = true
+	return path.Base(p) + "." + strings.ToUpper(fn[:1]) + fn[1:]
+}
+
+// stdFunc returns the Go function of a name qualified by a standard module.
+func (ev *ExportGoVisitor) stdFunc(name string) (goFunc, bool) {
+	var i = strings.Index(name, ".")
+	if i < 0 {
+		return goFunc{}, false
+	}
+	m, ok := ev.std[name[:i]]
+	if !ok {
+		return goFunc{}, false
+	}
+	f, ok := goStdlib[m+name[i:]]
+	return f, ok
+}
+
+func goType(t string) string {
+	return types.MapTypeNames(t, func(name string) string {
+		if g, ok := goTypes[name]; ok {
+			return g
+		}
+		return name
+	})
+}
+
+func goTypeList(list []string) []string {
+	var r []string
+	for _, t := range list {
+		r = append(r, goType(t))
+	}
+	return r
+}
+
+func goZero(t string) string {
+	switch t {
+	case "int", "float64":
+		return "0"
+	case "string":
+		return `""`
+	case "bool":
+		return "false"
+	}
+	return t + "{}"
+}
@@ -0,0 +1,184 @@
+// Package diag holds the errors reported about myc programs and shows them
+// with their source line.
+package diag
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+
+	"myc/token"
+)
+
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+	SeverityNote
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	case SeverityNote:
+		return "note"
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// Diagnostic is a message about a position in a myc source file.
+// Line and Column start at 1, zero means the position is unknown.
+type Diagnostic struct {
+	Pos      token.Pos // turned into File, Line and Column by Resolve
+	File     string
+	Line     int
+	Column   int
+	Severity Severity
+	Message  string
+}
+
+func (d Diagnostic) Error() string {
+	var pos = d.File
+	if d.Line > 0 {
+		if pos != "" {
+			pos += ":"
+		}
+		pos += fmt.Sprint(d.Line)
+		if d.Column > 0 {
+			pos += fmt.Sprintf(":%d", d.Column)
+		}
+	}
+	if pos == "" {
+		return fmt.Sprintf("%s: %s", d.Severity, d.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", pos, d.Severity, d.Message)
+}
+
+// Render writes the diagnostic followed by the offending line of src and a
+// caret under the column.
+func (d Diagnostic) Render(w io.Writer, src []byte) {
+	fmt.Fprintln(w, d.Error())
+	var lines = bytes.Split(src, []byte("\n"))
+	if d.Line <= 0 || d.Line > len(lines) {
+		return
+	}
+	var line = strings.TrimRight(string(lines[d.Line-1]), "\r")
+	var prefix = fmt.Sprintf("%5d | ", d.Line)
+	fmt.Fprintf(w, "%s%s\n", prefix, line)
+	if d.Column <= 0 {
+		return
+	}
+	// keep tabs and wide characters so that the caret lines up with the source
+	var caret []byte
+	var column = 1
+	for _, r := range line {
+		if column >= d.Column {
+			break
+		}
+		switch {
+		case r == '\t':
+			caret = append(caret, '\t')
+		case wide(r):
+			caret = append(caret, "  "...)
+		default:
+			caret = append(caret, ' ')
+		}
+		column++
+	}
+	fmt.Fprintf(w, "%*s | %s^\n", len(prefix)-3, "", caret)
+}
+
+// wide reports whether r takes two columns in a terminal, like the CJK
+// characters do.
+func wide(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hangul, unicode.Hiragana, unicode.Katakana) ||
+		0x3000 <= r && r <= 0x303F || // CJK symbols and punctuation
+		0xFF01 <= r && r <= 0xFF60 // fullwidth forms
+}
+
+// Diagnostics is a list of diagnostics, it is used as an error when any
+// of them is an error.
+type Diagnostics []Diagnostic
+
+func (list Diagnostics) Error() string {
+	switch len(list) {
+	case 0:
+		return "no errors"
+	case 1:
+		return list[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", list[0], len(list)-1)
+}
+
+// Err returns list as an error if it contains errors, otherwise nil.
+func (list Diagnostics) Err() error {
+	for _, d := range list {
+		if d.Severity == SeverityError {
+			return list
+		}
+	}
+	return nil
+}
+
+// Sort sorts the list by position.
+func (list Diagnostics) Sort() {
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].File != list[j].File {
+			return list[i].File < list[j].File
+		}
+		if list[i].Line != list[j].Line {
+			return list[i].Line < list[j].Line
+		}
+		return list[i].Column < list[j].Column
+	})
+}
+
+// positioner is a FileSet or a File.
+type positioner interface {
+	Position(p token.Pos) token.Position
+}
+
+// Resolve fills in the file, line and column of diagnostics with a Pos.
+func (list Diagnostics) Resolve(fset positioner) {
+	for i := range list {
+		list[i].Resolve(fset)
+	}
+}
+
+func (d *Diagnostic) Resolve(fset positioner) {
+	if !d.Pos.IsValid() {
+		return
+	}
+	var pos = fset.Position(d.Pos)
+	if pos.IsValid() {
+		d.File, d.Line, d.Column = pos.Filename, pos.Line, pos.Column
+	}
+}
+
+// Errorf returns an error at pos.
+func Errorf(pos token.Pos, format string, args ...interface{}) Diagnostic {
+	return Diagnostic{
+		Pos:      pos,
+		Severity: SeverityError,
+		Message:  fmt.Sprintf(format, args...),
+	}
+}
+
+// Catch recovers from a panic with a Diagnostic and stores it in *err.
+// Other panics are bugs and are not recovered.
+func Catch(err *error) {
+	if r := recover(); r != nil {
+		d, ok := r.(Diagnostic)
+		if !ok {
+			panic(r)
+		}
+		*err = d
+	}
+}
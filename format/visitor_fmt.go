@@ -0,0 +1,368 @@
+// Package format prints myc programs in their canonical layout.
+package format
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+
+	"myc/ast"
+	"myc/diag"
+	"myc/lexer"
+	"myc/parser"
+	"myc/token"
+)
+
+// NewFormatVisitor returns a visitor that writes ast in the canonical layout
+// of myc programs. comments are the comments of the source in order, they
+// are put back between the nodes. file gives the lines of the nodes.
+func NewFormatVisitor(node ast.AST, file *token.File, comments []token.Comment, w io.Writer) *FormatVisitor {
+	return &FormatVisitor{
+		ast:      node,
+		file:     file,
+		comments: comments,
+		Writer:   w,
+	}
+}
+
+// FormatVisitor writes one statement per line, blocks are always in braces
+// and indented with tabs, binary operators are surrounded by spaces. Single
+// blank lines between statements are kept.
+type FormatVisitor struct {
+	ast      ast.AST
+	file     *token.File
+	comments []token.Comment // the comments not written yet
+
+	buf    bytes.Buffer
+	indent int
+	line   int  // the source line of the last thing written
+	first  bool // nothing was written in the current block yet
+
+	io.Writer
+}
+
+// Exec writes the formatted program.
+func (ev *FormatVisitor) Exec() (err error) {
+	defer diag.Catch(&err)
+	var list []ast.AST
+	if project, ok := ev.ast.(ast.ASTProject); ok {
+		for _, imp := range project.Imports {
+			list = append(list, imp)
+		}
+		list = append(list, project.StmtList.(ast.ASTStmt).List...)
+	} else {
+		list = append(list, ev.ast)
+	}
+	ev.first = true
+	ev.stmts(list, token.NoPos)
+	ev.own(token.NoPos)
+	_, err = ev.Write(ev.buf.Bytes())
+	return err
+}
+
+// Source returns src in the canonical layout, src must not have
+// syntax errors.
+func Source(file *token.File, src []byte) ([]byte, error) {
+	var l = lexer.NewFileLexer(file, src)
+	var p = parser.NewLexerParse(l)
+	var node = p.Parse()
+	if err := append(l.Diagnostics(), p.Diagnostics()...).Err(); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	err := NewFormatVisitor(node, file, p.Comments(), &buf).Exec()
+	return buf.Bytes(), err
+}
+
+func (ev *FormatVisitor) errorf(node ast.AST, format string, args ...interface{}) {
+	panic(diag.Errorf(ast.PosOf(node), format, args...))
+}
+
+func (ev *FormatVisitor) lineOf(pos token.Pos) int {
+	return ev.file.Position(pos).Line
+}
+
+// before reports whether c is in front of limit, NoPos is no limit.
+func before(c token.Comment, limit token.Pos) bool {
+	return limit == token.NoPos || c.Pos < limit
+}
+
+// commentText returns the text of c without trailing blanks.
+func commentText(c token.Comment) string {
+	if strings.HasPrefix(c.Text, "//") {
+		return strings.TrimRight(c.Text, " \t")
+	}
+	return c.Text
+}
+
+// startLine starts an output line for something on line of the source,
+// keeping one blank line where the source has some.
+func (ev *FormatVisitor) startLine(line int) {
+	if !ev.first && line > ev.line+1 {
+		ev.buf.WriteByte('\n')
+	}
+	ev.first = false
+	ev.buf.WriteString(strings.Repeat("\t", ev.indent))
+}
+
+// own writes the comments in front of limit on lines of their own.
+func (ev *FormatVisitor) own(limit token.Pos) {
+	for len(ev.comments) > 0 && before(ev.comments[0], limit) {
+		var c = ev.comments[0]
+		ev.comments = ev.comments[1:]
+		ev.startLine(ev.lineOf(c.Pos))
+		ev.buf.WriteString(commentText(c))
+		ev.buf.WriteByte('\n')
+		ev.line = ev.lineOf(c.End - 1)
+	}
+}
+
+// trailing ends the line of a node ending at end. The comments inside the
+// node that are still left and the ones after it on the same source line
+// are written at the end of the line, as long as they are in front of limit.
+func (ev *FormatVisitor) trailing(end, limit token.Pos) {
+	var line = ev.lineOf(end - 1)
+	var sep = " "
+	for len(ev.comments) > 0 {
+		var c = ev.comments[0]
+		if !before(c, limit) || c.Pos >= end && ev.lineOf(c.Pos) != line {
+			break
+		}
+		ev.comments = ev.comments[1:]
+		ev.buf.WriteString(sep + commentText(c))
+		if strings.HasPrefix(c.Text, "//") {
+			// nothing can follow a line comment on its line
+			sep = "\n" + strings.Repeat("\t", ev.indent)
+		}
+		if l := ev.lineOf(c.End - 1); l > line {
+			line = l
+		}
+	}
+	ev.buf.WriteByte('\n')
+	ev.line = line
+}
+
+// inline writes the block comments in front of pos. Line comments wait for
+// the end of the line.
+func (ev *FormatVisitor) inline(pos token.Pos) {
+	for len(ev.comments) > 0 && ev.comments[0].Pos < pos && strings.HasPrefix(ev.comments[0].Text, "/*") {
+		ev.buf.WriteString(ev.comments[0].Text + " ")
+		ev.comments = ev.comments[1:]
+	}
+}
+
+// stmts writes list one statement per line. The comments up to limit are
+// written with the statements.
+func (ev *FormatVisitor) stmts(list []ast.AST, limit token.Pos) {
+	for i, node := range list {
+		if _, ok := node.(ast.ASTEmpty); ok {
+			continue
+		}
+		ev.own(node.Pos())
+		ev.startLine(ev.lineOf(node.Pos()))
+		ev.stmt(node)
+		var next = limit
+		for _, n := range list[i+1:] {
+			if _, ok := n.(ast.ASTEmpty); !ok {
+				next = n.Pos()
+				break
+			}
+		}
+		ev.trailing(node.End(), next)
+	}
+}
+
+// body writes a block in braces, a single statement of an if is put in a
+// block of its own.
+func (ev *FormatVisitor) body(node ast.AST) {
+	var list = []ast.AST{node}
+	block, isBlock := node.(ast.ASTStmt)
+	if isBlock {
+		list = block.List
+	}
+	var empty = len(ev.comments) == 0 || ev.comments[0].Pos >= node.End()
+	for _, stmt := range list {
+		if _, ok := stmt.(ast.ASTEmpty); !ok {
+			empty = false
+		}
+	}
+	if empty {
+		ev.buf.WriteString("{}")
+		return
+	}
+	ev.buf.WriteString("{")
+	if isBlock {
+		// comments behind the brace stay on its line
+		var first = node.End()
+		if len(list) > 0 {
+			first = list[0].Pos()
+		}
+		ev.trailing(node.Pos()+1, first)
+	} else {
+		ev.buf.WriteByte('\n')
+	}
+	ev.indent++
+	ev.first = true
+	ev.stmts(list, node.End())
+	ev.own(node.End())
+	ev.indent--
+	ev.first = false
+	ev.buf.WriteString(strings.Repeat("\t", ev.indent) + "}")
+}
+
+func (ev *FormatVisitor) stmt(node ast.AST) {
+	switch node := node.(type) {
+	case ast.ASTImport:
+		ev.buf.WriteString("import " + strconv.Quote(node.Path))
+	case ast.ASTStmt:
+		ev.body(node)
+	case ast.ASTAssign:
+		if node.IsDefined {
+			ev.buf.WriteString("var ")
+		}
+		ev.vars(node.Left)
+		ev.buf.WriteString(" " + node.Op + " ")
+		ev.exprs(node.Right)
+	case ast.ASTBranch:
+		ev.buf.WriteString("if ")
+		ev.expr(node.Cond, 0)
+		ev.buf.WriteString(" ")
+		ev.body(node.Then)
+		switch f := node.Else.(type) {
+		case nil:
+		case ast.ASTBranch:
+			ev.buf.WriteString(" else ")
+			ev.stmt(f)
+		default:
+			ev.buf.WriteString(" else ")
+			ev.body(f)
+		}
+	case ast.ASTWhile:
+		ev.buf.WriteString("while ")
+		ev.expr(node.Cond, 0)
+		ev.buf.WriteString(" ")
+		ev.body(node.Body)
+	case ast.ASTFor:
+		ev.buf.WriteString("for ")
+		if node.Init != nil || node.Cond != nil || node.Post != nil {
+			if node.Init != nil {
+				ev.stmt(node.Init)
+			}
+			ev.buf.WriteString(";")
+			if node.Cond != nil {
+				ev.buf.WriteString(" ")
+				ev.expr(node.Cond, 0)
+			}
+			ev.buf.WriteString(";")
+			if node.Post != nil {
+				ev.buf.WriteString(" ")
+				ev.stmt(node.Post)
+			}
+			ev.buf.WriteString(" ")
+		}
+		ev.body(node.Body)
+	case ast.ASTRange:
+		ev.buf.WriteString("for " + node.Key.Name + " in ")
+		ev.expr(node.From, 0)
+		ev.buf.WriteString(" .. ")
+		ev.expr(node.To, 0)
+		ev.buf.WriteString(" ")
+		ev.body(node.Body)
+	case ast.ASTBreak:
+		ev.buf.WriteString("break")
+	case ast.ASTContinue:
+		ev.buf.WriteString("continue")
+	case ast.ASTFunction:
+		ev.buf.WriteString("func " + node.Name.Name + "(")
+		ev.vars(node.Params)
+		ev.buf.WriteString(")")
+		switch len(node.Results) {
+		case 0:
+		case 1:
+			ev.buf.WriteString(" " + node.Results[0].Type)
+		default:
+			var types []string
+			for _, r := range node.Results {
+				types = append(types, r.Type)
+			}
+			ev.buf.WriteString(" (" + strings.Join(types, ", ") + ")")
+		}
+		ev.buf.WriteString(" ")
+		ev.body(node.Body)
+	case ast.ASTReturn:
+		ev.buf.WriteString("return")
+		if len(node.Exprs) > 0 {
+			ev.buf.WriteString(" ")
+			ev.exprs(node.Exprs)
+		}
+		if node.Error != "" {
+			ev.buf.WriteString(": " + node.Error)
+		}
+	default:
+		ev.expr(node, 0)
+	}
+}
+
+// vars writes declared variables with their types.
+func (ev *FormatVisitor) vars(list []ast.ASTVariable) {
+	for i, v := range list {
+		if i > 0 {
+			ev.buf.WriteString(", ")
+		}
+		ev.inline(v.Pos())
+		ev.buf.WriteString(v.Name)
+		if v.Type != "" {
+			ev.buf.WriteString(" " + v.Type)
+		}
+	}
+}
+
+func (ev *FormatVisitor) exprs(list []ast.AST) {
+	for i, node := range list {
+		if i > 0 {
+			ev.buf.WriteString(", ")
+		}
+		ev.expr(node, 0)
+	}
+}
+
+// expr writes ast, in parentheses when it binds looser than prec.
+func (ev *FormatVisitor) expr(node ast.AST, prec int) {
+	ev.inline(node.Pos())
+	switch node := node.(type) {
+	case ast.ASTBinaryOp:
+		var p, ok = ast.Prec[node.Op]
+		if !ok {
+			ev.errorf(node, "unknown operator %s", node.Op)
+		}
+		if p < prec {
+			ev.buf.WriteString("(")
+			defer ev.buf.WriteString(")")
+		}
+		ev.expr(node.Left, p)
+		ev.buf.WriteString(" " + node.Op + " ")
+		ev.expr(node.Right, p+1)
+	case ast.ASTUnaryOp:
+		ev.buf.WriteString(node.Op)
+		// - -a and & &a must not become -- and &&
+		if inner, ok := node.Operand.(ast.ASTUnaryOp); ok && inner.Op == node.Op && (node.Op == "-" || node.Op == "&") {
+			ev.buf.WriteString(" ")
+		}
+		ev.expr(node.Operand, ast.UnaryPrec)
+	case ast.ASTNumber:
+		ev.buf.WriteString(node.Lit)
+	case ast.ASTString:
+		ev.buf.WriteString(node.Lit)
+	case ast.ASTBool:
+		ev.buf.WriteString(strconv.FormatBool(node.Value))
+	case ast.ASTVariable:
+		ev.buf.WriteString(node.Name)
+	case ast.ASTCallFunc:
+		ev.buf.WriteString(node.Name.Name + "(")
+		ev.exprs(node.Args)
+		ev.buf.WriteString(")")
+	default:
+		ev.errorf(node, "cannot format %T", node)
+	}
+}
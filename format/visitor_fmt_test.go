@@ -0,0 +1,94 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"myc/lexer"
+	"myc/parser"
+	"myc/token"
+)
+
+var fmtTests = []struct {
+	src  string
+	want string
+}{
+	{"var a=1+2*3\n", "var a = 1 + 2 * 3\n"},
+	{"var a   =(1+2)*3\nvar b = a-(2-1)\n", "var a = (1 + 2) * 3\nvar b = a - (2 - 1)\n"},
+	{"var x int=0x1F\nvar y = 1_000.5\n", "var x int = 0x1F\nvar y = 1_000.5\n"},
+	{"var s = \"a\\tb\"\nvar r = `raw\nstring`\n", "var s = \"a\\tb\"\nvar r = `raw\nstring`\n"},
+	{"var t = true and not false or 1<2\n", "var t = true && !false || 1 < 2\n"},
+	{"var u = -(1+2) as float\nvar v = !true\n", "var u = -(1 + 2) as float\nvar v = !true\n"},
+	{"func add(a int,b int) int {return a+b}\n", "func add(a int, b int) int {\n\treturn a + b\n}\n"},
+	{
+		"func div(a int, b int)(int,int){\n\n\n\treturn a/b, a%b\n}\nvar q, r = div(7, 2)\n",
+		"func div(a int, b int) (int, int) {\n\treturn a / b, a % b\n}\nvar q, r = div(7, 2)\n",
+	},
+	{
+		"if 1<2 {var a=1} else if 2<3 {var b=2} else {var c=3}\n",
+		"if 1 < 2 {\n\tvar a = 1\n} else if 2 < 3 {\n\tvar b = 2\n} else {\n\tvar c = 3\n}\n",
+	},
+	{
+		"var i = 0\nwhile i<10 { i+=1\nif i==5 {break}\n}\n",
+		"var i = 0\nwhile i < 10 {\n\ti += 1\n\tif i == 5 {\n\t\tbreak\n\t}\n}\n",
+	},
+	{"for i in 0..10 {\n\tif i%2==0 {continue}\n}\n", "for i in 0 .. 10 {\n\tif i % 2 == 0 {\n\t\tcontinue\n\t}\n}\n"},
+	{"import \"stdio\"\nstdio.printf(\"%d %d\\n\",\n\t1,\n\t2,\n)\n", "import \"stdio\"\nstdio.printf(\"%d %d\\n\", 1, 2)\n"},
+	{
+		"// leading\nvar a = 1 // trailing\n\n\n\n/* block */\nfunc f() {\n\t// inside\n}\n",
+		"// leading\nvar a = 1 // trailing\n\n/* block */\nfunc f() {\n\t// inside\n}\n",
+	},
+	{
+		"func outer() int {\n\tfunc inner(n int) int { return n*2 }\n\treturn inner(3)\n}\n",
+		"func outer() int {\n\tfunc inner(n int) int {\n\t\treturn n * 2\n\t}\n\treturn inner(3)\n}\n",
+	},
+}
+
+// tree returns the syntax tree of src without positions and without the
+// empty statements of the line breaks.
+func tree(t *testing.T, src []byte) string {
+	var l = lexer.NewFileLexer(token.NewFileSet().AddFile("", len(src)), src)
+	var p = parser.NewLexerParse(l)
+	var node = p.Parse()
+	if err := append(l.Diagnostics(), p.Diagnostics()...).Err(); err != nil {
+		t.Fatalf("%q: %v", src, err)
+	}
+	return strings.NewReplacer(" (VOID)", "", "(VOID) ", "").Replace(fmt.Sprint(node))
+}
+
+func formatted(t *testing.T, src []byte) []byte {
+	out, err := Source(token.NewFileSet().AddFile("", len(src)), src)
+	if err != nil {
+		t.Fatalf("%q: %v", src, err)
+	}
+	return out
+}
+
+func TestFormat(t *testing.T) {
+	for _, test := range fmtTests {
+		if got := formatted(t, []byte(test.src)); string(got) != test.want {
+			t.Errorf("%q:\ngot\n%s\nwant\n%s", test.src, got, test.want)
+		}
+	}
+}
+
+func TestFormatIdempotent(t *testing.T) {
+	for _, test := range fmtTests {
+		var once = formatted(t, []byte(test.src))
+		var twice = formatted(t, once)
+		if string(once) != string(twice) {
+			t.Errorf("%q:\nformatted once:\n%s\nformatted twice:\n%s", test.src, once, twice)
+		}
+	}
+}
+
+func TestFormatSameAST(t *testing.T) {
+	for _, test := range fmtTests {
+		var want = tree(t, []byte(test.src))
+		var out = formatted(t, []byte(test.src))
+		if got := tree(t, out); got != want {
+			t.Errorf("%q formatted as\n%s\nchanges the syntax tree\n%s\nto\n%s", test.src, out, want, got)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package myc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// golden runs testdata/c/golden.sh on the programs in dir.
+func golden(t *testing.T, dir string) (string, error) {
+	if _, err := exec.LookPath("cc"); err != nil {
+		t.Skip("no C compiler")
+	}
+	out, err := exec.Command("sh", "testdata/c/golden.sh", dir).CombinedOutput()
+	return string(out), err
+}
+
+func TestGoldenC(t *testing.T) {
+	if out, err := golden(t, "testdata/c"); err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+}
+
+func TestGoldenCFailure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var src = "func main() {\n\tvar x = 1 +\n}\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "bad.myc"), []byte(src), 0666); err != nil {
+		t.Fatal(err)
+	}
+	out, err := golden(t, dir)
+	if err == nil {
+		t.Fatalf("golden.sh passed a program that does not compile:\n%s", out)
+	}
+	// the whole diagnostic is shown, with its source line
+	for _, want := range []string{"bad.myc:2:", "\tvar x = 1 +\n", "^", "FAIL: bad.myc"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+}
+
+// TestGoldenGo checks the Go backend: every program of testdata/c is
+// compiled to Go, built and run, its output must be the output of the
+// interpreter and its output and exit status the .out file of the C
+// backend.
+func TestGoldenGo(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("no go command")
+	}
+	dir, err := ioutil.TempDir("", "golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files, err := filepath.Glob("testdata/c/*.myc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range files {
+		src, err := ioutil.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		prog, diags := CompileFile(name, src)
+		if len(diags) > 0 {
+			t.Errorf("%v", diags)
+			continue
+		}
+		var want bytes.Buffer
+		prog.SetOutput(&want)
+		if err := prog.Run(context.Background()); err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+
+		var base = strings.TrimSuffix(filepath.Base(name), ".myc")
+		var gosrc bytes.Buffer
+		if err := prog.WriteGo(&gosrc); err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		var file = filepath.Join(dir, base+".go")
+		if err := ioutil.WriteFile(file, gosrc.Bytes(), 0666); err != nil {
+			t.Fatal(err)
+		}
+		var bin = filepath.Join(dir, base)
+		if out, err := exec.Command("go", "build", "-o", bin, file).CombinedOutput(); err != nil {
+			t.Errorf("%s: go build: %v\n%s", name, err, out)
+			continue
+		}
+		out, err := exec.Command(bin).Output()
+		var status int
+		if e, ok := err.(*exec.ExitError); ok {
+			status = e.ExitCode()
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		if string(out) != want.String() {
+			t.Errorf("%s: go output\n%s\ninterpreter output\n%s", name, out, want.String())
+		}
+		golden, err := ioutil.ReadFile(strings.TrimSuffix(name, ".myc") + ".out")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := fmt.Sprintf("%sexit status %d\n", out, status); got != string(golden) {
+			t.Errorf("%s: go output\n%s\nwant\n%s", name, got, golden)
+		}
+	}
+}
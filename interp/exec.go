@@ -0,0 +1,484 @@
+// Package interp runs myc programs by walking their syntax tree.
+package interp
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"myc/ast"
+	"myc/diag"
+	"myc/trace"
+	"myc/types"
+)
+
+func NewExecVisitor(node ast.AST) *ExecVisitor {
+	return &ExecVisitor{
+		ast: node,
+	}
+}
+
+// maxCallDepth limits the recursion of myc functions.
+const maxCallDepth = 10000
+
+type ExecVisitor struct {
+	ast    ast.AST
+	st     *SymbolTable
+	global *SymbolTable
+	depth  int // number of active calls
+	trace  trace.Tracer
+	done   <-chan struct{}
+	status int // returned by main, see Status
+
+	modules map[string]Module // registered with ev.Register
+	allowed map[string]bool   // restricted standard modules
+	out     io.Writer         // output of the programs, see SetOutput
+}
+
+// SetTracer makes the interpreter report every node it executes to t.
+func (ev *ExecVisitor) SetTracer(t trace.Tracer) {
+	ev.trace = t
+}
+
+// returnValues is the result of executing a return statement, it stops
+// the statements around it until it reaches the function call.
+type returnValues []Value
+
+// Exec runs the program and then its main function if there is one. A
+// runtime error stops it and is returned as a Diagnostic.
+func (ev *ExecVisitor) Exec() error {
+	return ev.ExecContext(context.Background())
+}
+
+// ExecContext is Exec for a program that must stop when ctx is done, it
+// then returns ctx.Err().
+func (ev *ExecVisitor) ExecContext(ctx context.Context) (err error) {
+	defer func() {
+		if ctx.Err() != nil && err != nil {
+			err = ctx.Err()
+		}
+	}()
+	defer diag.Catch(&err)
+	ev.done = ctx.Done()
+	ev.st = NewSymbolTable(nil)
+	ev.global = ev.st
+	ev.outsideLoop(ev.exec(ev.ast))
+	ev.status = 0
+	if s := ev.global.Get("main"); s != nil && s.t == "func" {
+		var fn = s.value.(*FuncValue).fn
+		var r = ev.call(ast.ASTCallFunc{Span: fn.Span, Name: fn.Name})
+		if len(r) == 1 {
+			if n, ok := r[0].(IntValue); ok {
+				ev.status = int(n)
+			}
+		}
+	}
+	return nil
+}
+
+// Status returns the int that the main function of the program returned,
+// the exit status of the program. It is 0 when there is no main function
+// or it returns no int.
+func (ev *ExecVisitor) Status() int {
+	return ev.status
+}
+
+// Eval runs another program in the global scope of the programs run by
+// Eval before it, like the lines of the REPL. It returns the values of the
+// last statement when that is an expression. The main function is not
+// called. A program that fails declares nothing, the names it declared
+// before the error are removed again.
+func (ev *ExecVisitor) Eval(ctx context.Context, node ast.ASTProject) (values []Value, err error) {
+	if ev.global == nil {
+		ev.global = NewSymbolTable(nil)
+	}
+	var declared = make(map[string]bool)
+	for name := range ev.global.t {
+		declared[name] = true
+	}
+	defer func() {
+		if err == nil {
+			return
+		}
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		for name := range ev.global.t {
+			if !declared[name] {
+				delete(ev.global.t, name)
+			}
+		}
+	}()
+	defer diag.Catch(&err)
+	ev.done = ctx.Done()
+	ev.st = ev.global
+	ev.declare(node)
+	var list = []ast.AST{node.StmtList}
+	if stmt, ok := node.StmtList.(ast.ASTStmt); ok {
+		list = stmt.List
+	}
+	for _, a := range list {
+		if _, ok := a.(ast.ASTEmpty); ok {
+			continue
+		}
+		var r = ev.exec(a)
+		ev.outsideLoop(r)
+		// statements have no value, even when their last statement is
+		// an expression, like the branch of an if
+		values = nil
+		if !ast.IsExpr(a) {
+			continue
+		}
+		switch r := r.(type) {
+		case Value:
+			values = []Value{r}
+		case []Value:
+			values = r
+		}
+	}
+	return values, nil
+}
+
+// errorf stops the program with a runtime error.
+func (ev *ExecVisitor) errorf(node ast.AST, format string, args ...interface{}) {
+	panic(diag.Errorf(ast.PosOf(node), format, args...))
+}
+
+// interrupt stops the program at node when its context is done.
+func (ev *ExecVisitor) interrupt(node ast.AST) {
+	select {
+	case <-ev.done:
+		ev.errorf(node, "interrupted")
+	default:
+	}
+}
+
+func (ev *ExecVisitor) check(node ast.AST, err error) {
+	if err != nil {
+		ev.errorf(node, "%v", err)
+	}
+}
+
+// value evaluates an expression that must have a single value.
+func (ev *ExecVisitor) value(node ast.AST) Value {
+	switch v := ev.exec(node).(type) {
+	case Value:
+		return v
+	case []Value:
+		ev.errorf(node, "multiple-value %s in single-value context", ast.ExprString(node))
+	case nil:
+		if _, ok := node.(ast.ASTCallFunc); ok {
+			ev.errorf(node, "%s (no value) used as value", ast.ExprString(node))
+		}
+	}
+	ev.errorf(node, "%s is not a value", ast.ExprString(node))
+	return nil
+}
+
+// call calls a myc or Go function and returns its results.
+func (ev *ExecVisitor) call(node ast.ASTCallFunc) []Value {
+	var s = ev.st.Get(node.Name.Name)
+	if s == nil {
+		ev.errorf(node.Name, "undefined: %s", node.Name.Name)
+	}
+	var fn *ast.ASTFunction
+	var env *SymbolTable
+	switch f := s.value.(type) {
+	case *FuncValue:
+		fn, env = f.fn, f.env
+	case *GoFunc:
+		var args []Value
+		for _, a := range node.Args {
+			args = append(args, ev.value(a))
+		}
+		ev.interrupt(node)
+		r, err := f.Call(args)
+		ev.check(node, err)
+		return r
+	default:
+		ev.errorf(node.Name, "cannot call non-function %s (%v)", node.Name.Name, s.value.Kind())
+	}
+	if len(node.Args) != len(fn.Params) {
+		ev.errorf(node, "wrong number of arguments in call to %s: have %d, want %d",
+			node.Name.Name, len(node.Args), len(fn.Params))
+	}
+	var args []Value
+	for _, a := range node.Args {
+		args = append(args, ev.value(a))
+	}
+	ev.interrupt(node)
+	if ev.depth >= maxCallDepth {
+		ev.errorf(node, "stack overflow in call to %s", node.Name.Name)
+	}
+
+	// every call gets its own scope on top of the one the function is
+	// declared in
+	var st = NewSymbolTable(env)
+	for i, p := range fn.Params {
+		ev.check(p, st.DefinedVar(p.Name, Convert(p.Type, args[i])))
+	}
+	var prev = ev.st
+	ev.st = st
+	ev.depth++
+	defer func() {
+		ev.st = prev
+		ev.depth--
+	}()
+
+	var result = ev.exec(fn.Body)
+	ev.outsideLoop(result)
+	r, _ := result.(returnValues)
+	if len(fn.Results) > 0 && len(r) != len(fn.Results) {
+		ev.errorf(node, "%s returned %d values, want %d", node.Name.Name, len(r), len(fn.Results))
+	}
+	return r
+}
+
+func (ev *ExecVisitor) exec(node ast.AST) interface{} {
+	if ev.trace != nil {
+		ev.trace.Trace(trace.Event{Kind: trace.Exec, Node: node})
+	}
+	switch node := node.(type) {
+	case ast.ASTProject:
+		ev.declare(node)
+		if stmt, ok := node.StmtList.(ast.ASTStmt); ok {
+			// the top level statements are in the global scope
+			return ev.stmts(stmt.List)
+		}
+		return ev.exec(node.StmtList)
+	case ast.ASTNumber:
+		if node.Kind == types.Float {
+			return FloatValue(node.Float)
+		}
+		return IntValue(node.Int)
+	case ast.ASTString:
+		return StringValue(node.Value)
+	case ast.ASTBool:
+		return BoolValue(node.Value)
+	case ast.ASTUnaryOp:
+		v, err := UnaryOp(node.Op, ev.value(node.Operand))
+		ev.check(node, err)
+		return v
+	case ast.ASTBinaryOp:
+		switch node.Op {
+		case "&&": // the right side is only evaluated when it decides
+			return BoolValue(Truth(ev.value(node.Left)) && Truth(ev.value(node.Right)))
+		case "||":
+			return BoolValue(Truth(ev.value(node.Left)) || Truth(ev.value(node.Right)))
+		case "as":
+			t, ok := node.Right.(ast.ASTVariable)
+			if !ok {
+				ev.errorf(node.Right, "%s is not a type", ast.ExprString(node.Right))
+			}
+			v, err := Cast(t.Name, ev.value(node.Left))
+			ev.check(node, err)
+			return v
+		}
+		v, err := BinaryOp(node.Op, ev.value(node.Left), ev.value(node.Right))
+		ev.check(node, err)
+		return v
+	case ast.ASTVariable:
+		tmp := ev.st.Get(node.Name)
+		if tmp == nil {
+			ev.errorf(node, "undefined: %s", node.Name)
+		}
+		return tmp.value
+	case ast.ASTStmt:
+		var prev = ev.st
+		ev.st = NewSymbolTable(prev)
+		defer func() { ev.st = prev }()
+		return ev.stmts(node.List)
+	case ast.ASTWhile:
+		return ev.loop(nil, node.Cond, nil, node.Body)
+	case ast.ASTFor:
+		// variables declared by init only live in the loop
+		var prev = ev.st
+		ev.st = NewSymbolTable(prev)
+		defer func() { ev.st = prev }()
+		if node.Init != nil {
+			ev.exec(node.Init)
+		}
+		return ev.loop(nil, node.Cond, node.Post, node.Body)
+	case ast.ASTRange:
+		var from, to = ev.value(node.From), ev.value(node.To)
+		var i, ok1 = from.(IntValue)
+		var n, ok2 = to.(IntValue)
+		if !ok1 || !ok2 {
+			ev.errorf(node, "cannot range from %v (%v) to %v (%v)", from, from.Kind(), to, to.Kind())
+		}
+		return ev.loop(func(st *SymbolTable) bool {
+			if i >= n {
+				return false
+			}
+			ev.check(node.Key, st.DefinedVar(node.Key.Name, i))
+			i++
+			return true
+		}, nil, nil, node.Body)
+	case ast.ASTBreak, ast.ASTContinue:
+		// stops the statements around it until it reaches the loop
+		return node
+	case ast.ASTAssign:
+		if node.IsDefined && node.Op != "=" {
+			ev.errorf(node, "unexpected %s in var declaration", node.Op)
+		}
+		var right []Value
+		if call, ok := node.Right[0].(ast.ASTCallFunc); ok && len(node.Right) == 1 && len(node.Left) > 1 {
+			// exp. var a,b=f()
+			right = ev.call(call)
+		} else {
+			for _, node := range node.Right {
+				right = append(right, ev.value(node))
+			}
+		}
+		if len(right) == 1 { // exp. var a,b,c=1
+			for i := range node.Left {
+				ev.assign(node, i, right[0])
+			}
+			return nil
+		}
+		if len(node.Left) != len(right) {
+			ev.errorf(node, "assignment mismatch: %d variables but %d values", len(node.Left), len(right))
+		}
+		for i := range node.Left {
+			ev.assign(node, i, right[i])
+		}
+		return nil
+	case ast.ASTEmpty, nil:
+		return nil
+	case ast.ASTBranch:
+		if Truth(ev.value(node.Cond)) {
+			return ev.body(node.Then)
+		} else {
+			return ev.body(node.Else)
+		}
+	case ast.ASTFunction:
+		// top level functions are declared by ASTProject already
+		if s := ev.st.t[node.Name.Name]; s == nil || s.t != "func" || s.value.(*FuncValue).fn.Pos() != node.Pos() {
+			ev.check(node.Name, ev.st.DefinedFunc(node))
+		}
+		return nil
+	case ast.ASTCallFunc:
+		var r = ev.call(node)
+		switch len(r) {
+		case 0:
+			return nil
+		case 1:
+			return r[0]
+		}
+		return r
+	case ast.ASTReturn:
+		var r = returnValues{}
+		for _, a := range node.Exprs {
+			r = append(r, ev.value(a))
+		}
+		return r
+	}
+	ev.errorf(node, "cannot evaluate %s", ast.ExprString(node))
+	return nil
+}
+
+// declare imports the modules of a program and declares its functions,
+// they can be called before they are declared.
+func (ev *ExecVisitor) declare(node ast.ASTProject) {
+	for _, i := range node.Imports {
+		ev.importModule(i)
+	}
+	if stmt, ok := node.StmtList.(ast.ASTStmt); ok {
+		for _, a := range stmt.List {
+			if fn, ok := a.(ast.ASTFunction); ok {
+				ev.check(fn.Name, ev.st.DefinedFunc(fn))
+			}
+		}
+	}
+}
+
+// importModule declares the functions of an imported module as name.func.
+func (ev *ExecVisitor) importModule(node ast.ASTImport) {
+	m, err := ev.module(node.Path)
+	ev.check(node, err)
+	var name = importName(node.Path)
+	for fname, fn := range m {
+		f, err := NewGoFunc(name+"."+fname, fn)
+		ev.check(node, err)
+		f.out = ev.output()
+		ev.check(node, ev.global.set(f.name, "func", f))
+	}
+}
+
+// stmts runs a list of statements until one of them returns, breaks or
+// continues.
+func (ev *ExecVisitor) stmts(list []ast.AST) interface{} {
+	for _, node := range list {
+		switch r := ev.exec(node).(type) {
+		case returnValues, ast.ASTBreak, ast.ASTContinue:
+			return r
+		}
+	}
+	return nil
+}
+
+// body runs the branch of an if in its own scope.
+func (ev *ExecVisitor) body(node ast.AST) interface{} {
+	var prev = ev.st
+	ev.st = NewSymbolTable(prev)
+	defer func() { ev.st = prev }()
+	return ev.exec(node)
+}
+
+// loop runs body while next and logic allow it, a nil logic is true.
+// Every iteration gets its own scope, next can define variables in it.
+func (ev *ExecVisitor) loop(next func(st *SymbolTable) bool, logic, post, body ast.AST) interface{} {
+	var prev = ev.st
+	defer func() { ev.st = prev }()
+	for {
+		ev.interrupt(body)
+		ev.st = prev
+		if logic != nil && !Truth(ev.value(logic)) {
+			return nil
+		}
+		ev.st = NewSymbolTable(prev)
+		if next != nil && !next(ev.st) {
+			return nil
+		}
+		switch r := ev.exec(body).(type) {
+		case returnValues:
+			return r
+		case ast.ASTBreak:
+			return nil
+		}
+		if post != nil {
+			ev.st = prev
+			ev.exec(post)
+		}
+	}
+}
+
+// outsideLoop reports a break or continue that is not in a loop.
+func (ev *ExecVisitor) outsideLoop(r interface{}) {
+	switch r := r.(type) {
+	case ast.ASTBreak:
+		ev.errorf(r, "break is not in a loop")
+	case ast.ASTContinue:
+		ev.errorf(r, "continue is not in a loop")
+	}
+}
+
+// assign stores value in the i-th variable on the left of ast.
+func (ev *ExecVisitor) assign(node ast.ASTAssign, i int, value Value) {
+	var name = node.Left[i].Name
+	if node.IsDefined {
+		ev.check(node.Left[i], ev.st.DefinedVar(name, Convert(node.Left[i].Type, value)))
+		return
+	}
+	if node.Op == "=" {
+		ev.check(node.Left[i], ev.st.SetVar(name, value))
+		return
+	}
+	s := ev.st.Get(name)
+	if s == nil {
+		ev.errorf(node.Left[i], "undefined: %s", name)
+	}
+	v, err := BinaryOp(strings.TrimSuffix(node.Op, "="), s.value, value)
+	ev.check(node, err)
+	ev.check(node.Left[i], ev.st.SetVar(name, v))
+}
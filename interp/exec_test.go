@@ -0,0 +1,229 @@
+package interp
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"myc/ast"
+	"myc/diag"
+	"myc/lexer"
+	"myc/parser"
+)
+
+// runGlobal runs src and returns the value of its global variable name, or
+// the message of the runtime error that stopped it.
+func runGlobal(src, name string) (value, err string) {
+	var p = parser.NewParse(lexer.NewLexer([]byte(src)).LexerToken())
+	var ev = NewExecVisitor(p.Parse())
+	if e := ev.Exec(); e != nil {
+		if d, ok := e.(diag.Diagnostic); ok {
+			return "", d.Message
+		}
+		return "", e.Error()
+	}
+	var s = ev.global.Get(name)
+	if s == nil {
+		return "", "undefined: " + name
+	}
+	return s.value.String(), ""
+}
+
+type execTest struct {
+	src   string
+	value string // of the global x
+	err   string
+}
+
+func testExec(t *testing.T, tests []execTest) {
+	t.Helper()
+	for _, test := range tests {
+		value, err := runGlobal(test.src, "x")
+		if value != test.value || err != test.err {
+			t.Errorf("%q: got %q, %q, want %q, %q", test.src, value, err, test.value, test.err)
+		}
+	}
+}
+
+func TestFunctions(t *testing.T) {
+	testExec(t, []execTest{
+		{"func add(a, b) { return a + b }\nvar x = add(1, 2)\n", "3", ""},
+		{"func fact(n) {\n\tif n {\n\t\treturn n * fact(n - 1)\n\t}\n\treturn 1\n}\nvar x = fact(5)\n", "120", ""},
+		{"func div(a, b)(int, int) { return a / b, a - a / b * b }\nvar q, r = div(7, 2)\nvar x = q * 10 + r\n", "31", ""},
+		{"var x = twice(4)\nfunc twice(n) { return n * 2 }\n", "8", ""},
+		{"var g = 10\nfunc get() { return g }\nvar x = get()\n", "10", ""},
+		{"var n = 1\nfunc id(n) { return n }\nvar x = id(5) + n\n", "6", ""},
+		{"var x = 1\nfunc set() {\n\tx = 2\n\treturn 0\n}\nset()\n", "2", ""},
+		{"func add(a, b) { return a + b }\nvar x = add(1)\n", "", "wrong number of arguments in call to add: have 1, want 2"},
+		{"var x = nope(1)\n", "", "undefined: nope"},
+		{"func loop(n) { return loop(n) }\nvar x = loop(1)\n", "", "stack overflow in call to loop"},
+		{"func two()(int, int) { return 1 }\nvar x, y = two()\n", "", "two returned 1 values, want 2"},
+	})
+}
+
+func TestValues(t *testing.T) {
+	testExec(t, []execTest{
+		{"var x = \"a\" + \"b\"\n", "ab", ""},
+		{"var x = 1 + 0.5\n", "1.5", ""},
+		{"var x = 7 / 2\n", "3", ""},
+		{"var x = 7.0 / 2\n", "3.5", ""},
+		{"var x = 1 == 1.0\n", "true", ""},
+		{"var x = \"a\" < \"b\"\n", "true", ""},
+		{"var x = 0\nif \"s\" {\n\tx = 1\n}\n", "1", ""},
+		{"var x = 1\nif 0.0 {\n\tx = 2\n}\n", "1", ""},
+		{"var x = \"a\" - 1\n", "", "invalid operation: a - 1 (mismatched types string and int)"},
+		{"var x = 1 / 0\n", "", "division by zero"},
+		{"var x = -\"a\"\n", "", "invalid operation: operator - not defined on a (string)"},
+	})
+}
+
+func TestLoops(t *testing.T) {
+	testExec(t, []execTest{
+		{"var x = 0\nvar i = 0\nwhile i < 5 {\n\tx += i\n\ti += 1\n}\n", "10", ""},
+		{"var x = 0\nwhile x < 0 {\n\tx = 1\n}\n", "0", ""},
+		{"var x = 0\nfor i in 0..4 {\n\tx = x * 10 + i\n}\n", "123", ""},
+		{"var x = 0\nfor i in 3..3 {\n\tx = 1\n}\n", "0", ""},
+		{"var x = 0\nfor var i = 0; i < 3; i += 1 {\n\tx += 2\n}\n", "6", ""},
+		{"var x = 0\nfor {\n\tx += 1\n\tif x == 4 {\n\t\tbreak\n\t}\n}\n", "4", ""},
+		{"var x = 0\nfor i in 0..10 {\n\tif i > 4 {\n\t\tcontinue\n\t}\n\tx += 1\n}\n", "5", ""},
+		{"var x = 0\nfor i in 0..3 {\n\tfor j in 0..3 {\n\t\tif j == 1 {\n\t\t\tbreak\n\t\t}\n\t\tx += 1\n\t}\n}\n", "3", ""},
+		{"func first() {\n\tfor i in 5..10 {\n\t\treturn i\n\t}\n\treturn 0\n}\nvar x = first()\n", "5", ""},
+		{"var x = 0\nbreak\n", "", "break is not in a loop"},
+		{"var x = 0\ncontinue\n", "", "continue is not in a loop"},
+		{"var x = 0\nfor i in 0..\"a\" {\n}\n", "", "cannot range from 0 (int) to a (string)"},
+	})
+}
+
+func TestOperators(t *testing.T) {
+	testExec(t, []execTest{
+		{"var x = 17 % 5 * 2\n", "4", ""},
+		{"var x = 1 << 4 | 1\n", "17", ""},
+		{"var x = ~0 & 0xff\n", "255", ""},
+		{"var x = 5\nx %= 3\n", "2", ""},
+		{"var x = 3\nx <<= 2\n", "12", ""},
+		{"var x = 12\nx >>= 1\n", "6", ""},
+		{"var x = 12\nx &= 10\n", "8", ""},
+		{"var x = 12\nx |= 3\n", "15", ""},
+		{"var x = 12\nx ^= 4\n", "8", ""},
+		{"var x = 1 % 0\n", "", "division by zero"},
+		{"var x = 1\nx <<= -1\n", "", "negative shift count -1"},
+	})
+}
+
+func TestBools(t *testing.T) {
+	testExec(t, []execTest{
+		{"var x = true\n", "true", ""},
+		{"var x = true and 1 < 2\n", "true", ""},
+		{"var x = not false or false\n", "true", ""},
+		{"var x = !true || false\n", "false", ""},
+		{"var x = 1 && 0\n", "false", ""},
+		{"var x = 0\nif 1 < 2 && 2 < 3 {\n\tx = 1\n}\n", "1", ""},
+		{"var x = 0\nif 1 < 2 and not (2 < 3) {\n\tx = 1\n}\n", "0", ""},
+		// the right side is only evaluated when it decides
+		{"func boom() { return 1 / 0 }\nvar x = false && boom() == 1\n", "false", ""},
+		{"func boom() { return 1 / 0 }\nvar x = true or boom() == 1\n", "true", ""},
+		{"func boom() { return 1 / 0 }\nvar x = true && boom() == 1\n", "", "division by zero"},
+		{"var x = 1.9 as int\n", "1", ""},
+		{"var x = true as float\n", "1", ""},
+		{"var x = \"a\" as int\n", "", "cannot convert a (string) to int"},
+	})
+}
+
+func TestScopes(t *testing.T) {
+	testExec(t, []execTest{
+		{"var x = 1\nif true {\n\tvar x = 2\n\tx = 3\n}\n", "1", ""},
+		{"var x = 1\nif true {\n\tx = 2\n}\n", "2", ""},
+		{"var x = 1\nif false {\n} else {\n\tvar x = 2\n}\n", "1", ""},
+		{"var x = 0\nwhile x < 3 {\n\tvar y = 1\n\tx += y\n}\n", "3", ""},
+		{"var x = 0\nfor i in 0..3 {\n\tvar y = i\n\tx += y\n}\nvar z = y\n", "", "undefined: y"},
+		{"func f() {\n\tvar x = 1\n\tvar x = 2\n}\nf()\n", "", "x redeclared in this block"},
+		{"func f() { return 1 }\nf = 2\n", "", "cannot assign to f"},
+	})
+}
+
+func TestClosures(t *testing.T) {
+	testExec(t, []execTest{
+		{"func counter() {\n\tvar n = 0\n\tfunc inc() {\n\t\tn += 1\n\t\treturn n\n\t}\n\treturn inc\n}\nvar c = counter()\nc()\nvar x = c()\n", "2", ""},
+		{"func counter() {\n\tvar n = 0\n\tfunc inc() {\n\t\tn += 1\n\t\treturn n\n\t}\n\treturn inc\n}\nvar a = counter()\nvar b = counter()\na()\na()\nvar x = b()\n", "1", ""},
+		{"func adder(n) {\n\tfunc add(m) { return n + m }\n\treturn add\n}\nvar add2 = adder(2)\nvar x = add2(5)\n", "7", ""},
+		{"var n = 1\nfunc get() { return n }\nfunc f() {\n\tvar n = 2\n\treturn get()\n}\nvar x = f()\n", "1", ""},
+		{"var n = 1\nfunc get() { return n }\nn = 5\nvar x = get()\n", "5", ""},
+	})
+}
+
+func TestStatus(t *testing.T) {
+	var tests = []struct {
+		src    string
+		status int
+	}{
+		{"var x = 1\n", 0},
+		{"func main() (int) { return 3 }\n", 3},
+		{"func main() (int) {\n\tvar n = 0\n\tfor i in 0..5 {\n\t\tn += i\n\t}\n\treturn n\n}\n", 10},
+		{"func main() { return }\n", 0},
+		{"func main() { return \"s\" }\n", 0},
+		{"func main() (int) { return 0 }\n", 0},
+	}
+	for _, test := range tests {
+		var p = parser.NewParse(lexer.NewLexer([]byte(test.src)).LexerToken())
+		var ev = NewExecVisitor(p.Parse())
+		if err := ev.Exec(); err != nil {
+			t.Errorf("%q: %v", test.src, err)
+			continue
+		}
+		if ev.Status() != test.status {
+			t.Errorf("%q: got status %d, want %d", test.src, ev.Status(), test.status)
+		}
+	}
+}
+
+func TestValueErrors(t *testing.T) {
+	// expressions are shown as they are written
+	testExec(t, []execTest{
+		{"func f()(int, int) { return 1, 2 }\nvar x = f() + 1\n", "", "multiple-value f() in single-value context"},
+		{"func f(a, b)(int, int) { return a, b }\nvar x = -f(1, 2 * 3)\n", "", "multiple-value f(1, 2 * 3) in single-value context"},
+		{"func f() { return }\nvar x = f()\n", "", "f() (no value) used as value"},
+		{"var x = 1 as (1 + 2)\n", "", "1 + 2 is not a type"},
+	})
+}
+
+func TestEval(t *testing.T) {
+	// the lines of a REPL session, only expressions have values
+	var tests = []struct {
+		src    string
+		values string
+		err    string
+	}{
+		{"var a = 1\n", "[]", ""},
+		{"a + 1\n", "[2]", ""},
+		{"a\n", "[1]", ""},
+		{"func f() (int, int) { return a, 2 }\n", "[]", ""},
+		{"f()\n", "[1 2]", ""},
+		{"a = 5\n", "[]", ""},
+		{"if a > 1 {\n\ta + 1\n}\n", "[]", ""},
+		{"if a > 1 {\n\ta + 1\n} else {\n\ta\n}\n", "[]", ""},
+		{"while a < 7 {\n\ta += 1\n}\n", "[]", ""},
+		{"for i in 0..2 {\n\ti\n}\n", "[]", ""},
+		{"{\n\ta\n}\n", "[]", ""},
+		{"a * 2\n", "[14]", ""},
+		{"var b = nope\n", "", "undefined: nope"},
+		{"b\n", "", "undefined: b"},
+	}
+	var ev = NewExecVisitor(nil)
+	for _, test := range tests {
+		var p = parser.NewParse(lexer.NewLexer([]byte(test.src)).LexerToken())
+		values, err := ev.Eval(context.Background(), p.Parse().(ast.ASTProject))
+		var msg string
+		if d, ok := err.(diag.Diagnostic); ok {
+			msg = d.Message
+		} else if err != nil {
+			msg = err.Error()
+		}
+		if msg != test.err {
+			t.Errorf("%q: got error %q, want %q", test.src, msg, test.err)
+			continue
+		}
+		if err == nil && fmt.Sprint(values) != test.values {
+			t.Errorf("%q: got %v, want %s", test.src, values, test.values)
+		}
+	}
+}
@@ -0,0 +1,334 @@
+package interp
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+
+	"myc/stdlib"
+)
+
+// Module is a set of Go functions that a program can import, by the name
+// it calls them with. A program that imports "stdio" calls the function
+// "printf" of the module as stdio.printf.
+//
+// The parameters and results of the functions can be of any int, uint or
+// float type, string, bool, Value or interface{}, the last parameter can
+// be variadic. A last result of type error that is not nil stops the
+// program with a runtime error. A first parameter of type io.Writer is not
+// passed from myc, it is the output of the interpreter, see SetOutput.
+type Module map[string]interface{}
+
+// modules are the modules registered with Register. Interpreters can run
+// while Register is called, modulesMu guards the map.
+var (
+	modulesMu sync.RWMutex
+	modules   = make(map[string]Module)
+)
+
+// Register makes m importable under path by every interpreter. It returns
+// an error if a function of m cannot be called from myc.
+func Register(path string, m Module) error {
+	if err := checkModule(path, m); err != nil {
+		return err
+	}
+	modulesMu.Lock()
+	modules[path] = m
+	modulesMu.Unlock()
+	return nil
+}
+
+// Register makes m importable under path by the programs ev runs, it
+// hides a module registered under the same path with the package Register.
+func (ev *ExecVisitor) Register(path string, m Module) error {
+	if err := checkModule(path, m); err != nil {
+		return err
+	}
+	if ev.modules == nil {
+		ev.modules = make(map[string]Module)
+	}
+	ev.modules[path] = m
+	return nil
+}
+
+func checkModule(path string, m Module) error {
+	for name, fn := range m {
+		if _, err := NewGoFunc(importName(path)+"."+name, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importName returns the name a program uses for the import path, the
+// last element without its extension, exp. stdio for "stdio.h".
+func importName(path string) string {
+	var name = path[strings.LastIndex(path, "/")+1:]
+	if i := strings.Index(name, "."); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+// SetOutput makes the programs ev runs write their output to w instead of
+// os.Stdout. It must be called before they import the modules that write.
+func (ev *ExecVisitor) SetOutput(w io.Writer) {
+	ev.out = w
+}
+
+// output returns the writer the programs ev runs write to.
+func (ev *ExecVisitor) output() io.Writer {
+	if ev.out == nil {
+		return os.Stdout
+	}
+	return ev.out
+}
+
+// Allow lets the programs ev runs import the restricted standard modules
+// of names, exp. os for files.
+func (ev *ExecVisitor) Allow(names ...string) {
+	if ev.allowed == nil {
+		ev.allowed = make(map[string]bool)
+	}
+	for _, name := range names {
+		ev.allowed[name] = true
+	}
+}
+
+// module returns the module imported by path, a registered one or else a
+// standard module. A path with an extension, like a C header, falls back
+// to the module without it.
+func (ev *ExecVisitor) module(path string) (Module, error) {
+	var dir = path[:strings.LastIndex(path, "/")+1]
+	for _, p := range []string{path, dir + importName(path)} {
+		if m, ok := ev.modules[p]; ok {
+			return m, nil
+		}
+		modulesMu.RLock()
+		m, ok := modules[p]
+		modulesMu.RUnlock()
+		if ok {
+			return m, nil
+		}
+	}
+	var std = stdlib.Lookup(path)
+	if std == nil {
+		return nil, fmt.Errorf("could not import %s (no module registered)", path)
+	}
+	if std.Restricted && !ev.allowed[std.Name] {
+		return nil, fmt.Errorf("could not import %s (not allowed)", path)
+	}
+	var m = make(Module)
+	for name, f := range std.Funcs {
+		m[name] = f.Impl
+	}
+	return m, nil
+}
+
+// GoFunc is a Go function that can be called from myc.
+type GoFunc struct {
+	name   string
+	fn     reflect.Value
+	writer bool      // the first parameter is an io.Writer
+	out    io.Writer // passed as it, os.Stdout when nil
+}
+
+var (
+	valueType  = reflect.TypeOf((*Value)(nil)).Elem()
+	errorType  = reflect.TypeOf((*error)(nil)).Elem()
+	writerType = reflect.TypeOf((*io.Writer)(nil)).Elem()
+)
+
+// NewGoFunc returns fn as a myc function named name. It returns an error
+// if the parameters or results of fn cannot be converted, see Module.
+func NewGoFunc(name string, fn interface{}) (*GoFunc, error) {
+	var v = reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return nil, fmt.Errorf("%s: %T is not a function", name, fn)
+	}
+	var t = v.Type()
+	var writer = t.NumIn() > 0 && t.In(0) == writerType && !(t.NumIn() == 1 && t.IsVariadic())
+	for i := 0; i < t.NumIn(); i++ {
+		if i == 0 && writer {
+			continue
+		}
+		var p = t.In(i)
+		if i == t.NumIn()-1 && t.IsVariadic() {
+			p = p.Elem()
+		}
+		if !goType(p) {
+			return nil, fmt.Errorf("%s: cannot use parameter of type %v", name, p)
+		}
+	}
+	for i := 0; i < t.NumOut(); i++ {
+		if r := t.Out(i); !goType(r) && (r != errorType || i != t.NumOut()-1) {
+			return nil, fmt.Errorf("%s: cannot use result of type %v", name, r)
+		}
+	}
+	return &GoFunc{name: name, fn: v, writer: writer}, nil
+}
+
+// goType reports whether values of t convert to and from Values.
+func goType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.String, reflect.Bool:
+		return true
+	case reflect.Interface:
+		return t == valueType || t.NumMethod() == 0
+	}
+	return false
+}
+
+func (*GoFunc) Kind() Kind       { return KindFunc }
+func (f *GoFunc) String() string { return "func " + f.name }
+
+// Call calls f with args converted to its parameter types and returns its
+// results as Values.
+func (f *GoFunc) Call(args []Value) ([]Value, error) {
+	var t = f.fn.Type()
+	var in []reflect.Value
+	if f.writer {
+		var out = f.out
+		if out == nil {
+			out = os.Stdout
+		}
+		in = append(in, reflect.ValueOf(&out).Elem())
+	}
+	var skip = len(in) // parameters that are not passed from myc
+	var n = t.NumIn() - skip
+	if t.IsVariadic() && len(args) < n-1 || !t.IsVariadic() && len(args) != n {
+		var want = fmt.Sprint(n)
+		if t.IsVariadic() {
+			want = fmt.Sprintf("%d or more", n-1)
+		}
+		return nil, fmt.Errorf("wrong number of arguments in call to %s: have %d, want %s", f.name, len(args), want)
+	}
+	for i, a := range args {
+		var p reflect.Type
+		if t.IsVariadic() && i >= n-1 {
+			p = t.In(t.NumIn() - 1).Elem()
+		} else {
+			p = t.In(skip + i)
+		}
+		v, err := toGo(a, p)
+		if err != nil {
+			return nil, fmt.Errorf("%v in argument to %s", err, f.name)
+		}
+		in = append(in, v)
+	}
+
+	var out = f.fn.Call(in)
+	if len(out) > 0 && t.Out(len(out)-1) == errorType {
+		if err, _ := out[len(out)-1].Interface().(error); err != nil {
+			return nil, err
+		}
+		out = out[:len(out)-1]
+	}
+	var results []Value
+	for _, r := range out {
+		v, err := FromGo(r.Interface())
+		if err != nil {
+			return nil, fmt.Errorf("%v in result of %s", err, f.name)
+		}
+		results = append(results, v)
+	}
+	return results, nil
+}
+
+// toGo converts v to a Go value of type t.
+func toGo(v Value, t reflect.Type) (reflect.Value, error) {
+	var r = reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.Interface:
+		if t == valueType {
+			r.Set(reflect.ValueOf(&v).Elem())
+		} else if x := GoValue(v); x != nil {
+			r.Set(reflect.ValueOf(x))
+		}
+		return r, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n, ok := v.(IntValue); ok {
+			if r.OverflowInt(int64(n)) {
+				return r, fmt.Errorf("%v overflows %v", n, t)
+			}
+			r.SetInt(int64(n))
+			return r, nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if n, ok := v.(IntValue); ok {
+			if n < 0 || r.OverflowUint(uint64(n)) {
+				return r, fmt.Errorf("%v overflows %v", n, t)
+			}
+			r.SetUint(uint64(n))
+			return r, nil
+		}
+	case reflect.Float32, reflect.Float64:
+		if f, ok := toFloat(v); ok {
+			r.SetFloat(f)
+			return r, nil
+		}
+	case reflect.String:
+		if s, ok := v.(StringValue); ok {
+			r.SetString(string(s))
+			return r, nil
+		}
+	case reflect.Bool:
+		if b, ok := v.(BoolValue); ok {
+			r.SetBool(bool(b))
+			return r, nil
+		}
+	}
+	return r, fmt.Errorf("cannot use %v (%v) as %v value", v, v.Kind(), t)
+}
+
+// GoValue returns v as a Go value: an int, float64, string, bool, nil or
+// the function itself.
+func GoValue(v Value) interface{} {
+	switch v := v.(type) {
+	case IntValue:
+		return int(v)
+	case FloatValue:
+		return float64(v)
+	case StringValue:
+		return string(v)
+	case BoolValue:
+		return bool(v)
+	case nil, NilValue:
+		return nil
+	}
+	return v
+}
+
+// FromGo returns the Value of a Go value. Ints, uints, floats, strings,
+// bools, Values and nil can be converted.
+func FromGo(x interface{}) (Value, error) {
+	if x == nil {
+		return NilValue{}, nil
+	}
+	if v, ok := x.(Value); ok {
+		return v, nil
+	}
+	var r = reflect.ValueOf(x)
+	switch r.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return IntValue(r.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var n = IntValue(r.Uint())
+		if n < 0 || uint64(n) != r.Uint() {
+			return nil, fmt.Errorf("%v overflows int", x)
+		}
+		return n, nil
+	case reflect.Float32, reflect.Float64:
+		return FloatValue(r.Float()), nil
+	case reflect.String:
+		return StringValue(r.String()), nil
+	case reflect.Bool:
+		return BoolValue(r.Bool()), nil
+	}
+	return nil, fmt.Errorf("cannot use value of type %T", x)
+}
@@ -0,0 +1,215 @@
+package interp
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"myc/lexer"
+	"myc/parser"
+)
+
+func TestFromGo(t *testing.T) {
+	var tests = []struct {
+		x    interface{}
+		want Value
+		err  string
+	}{
+		{nil, NilValue{}, ""},
+		{42, IntValue(42), ""},
+		{int8(-8), IntValue(-8), ""},
+		{uint16(7), IntValue(7), ""},
+		{uint64(math.MaxUint64), nil, "18446744073709551615 overflows int"},
+		{float32(0.5), FloatValue(0.5), ""},
+		{2.5, FloatValue(2.5), ""},
+		{"abc", StringValue("abc"), ""},
+		{true, BoolValue(true), ""},
+		{StringValue("v"), StringValue("v"), ""},
+		{[]int{1}, nil, "cannot use value of type []int"},
+		{struct{}{}, nil, "cannot use value of type struct {}"},
+	}
+	for _, test := range tests {
+		v, err := FromGo(test.x)
+		if msg := errString(err); msg != test.err {
+			t.Errorf("FromGo(%#v): got error %q, want %q", test.x, msg, test.err)
+			continue
+		}
+		if v != test.want {
+			t.Errorf("FromGo(%#v) = %#v, want %#v", test.x, v, test.want)
+		}
+	}
+}
+
+func TestToGo(t *testing.T) {
+	var tests = []struct {
+		v    Value
+		x    interface{} // a zero value gives the type
+		want interface{}
+		err  string
+	}{
+		{IntValue(42), 0, 42, ""},
+		{IntValue(-1), int8(0), int8(-1), ""},
+		{IntValue(300), int8(0), nil, "300 overflows int8"},
+		{IntValue(7), uint(0), uint(7), ""},
+		{IntValue(-1), uint(0), nil, "-1 overflows uint"},
+		{IntValue(2), 0.0, 2.0, ""},
+		{FloatValue(1.5), float32(0), float32(1.5), ""},
+		{StringValue("s"), "", "s", ""},
+		{BoolValue(true), false, true, ""},
+		{FloatValue(1.5), 0, nil, "cannot use 1.5 (float) as int value"},
+		{IntValue(1), "", nil, "cannot use 1 (int) as string value"},
+		{StringValue("1"), false, nil, "cannot use 1 (string) as bool value"},
+	}
+	for _, test := range tests {
+		r, err := toGo(test.v, reflect.TypeOf(test.x))
+		if msg := errString(err); msg != test.err {
+			t.Errorf("toGo(%v, %T): got error %q, want %q", test.v, test.x, msg, test.err)
+			continue
+		}
+		if err == nil && r.Interface() != test.want {
+			t.Errorf("toGo(%v, %T) = %#v, want %#v", test.v, test.x, r.Interface(), test.want)
+		}
+	}
+}
+
+func TestGoValue(t *testing.T) {
+	var tests = []struct {
+		v    Value
+		want interface{}
+	}{
+		{IntValue(1), 1},
+		{FloatValue(0.5), 0.5},
+		{StringValue("s"), "s"},
+		{BoolValue(false), false},
+		{NilValue{}, nil},
+		{nil, nil},
+	}
+	for _, test := range tests {
+		if x := GoValue(test.v); x != test.want {
+			t.Errorf("GoValue(%#v) = %#v, want %#v", test.v, x, test.want)
+		}
+	}
+}
+
+func TestNewGoFunc(t *testing.T) {
+	var tests = []struct {
+		fn  interface{}
+		err string
+	}{
+		{func(a int, b ...string) (float64, error) { return 0, nil }, ""},
+		{func(v Value, x interface{}) Value { return v }, ""},
+		{func(w io.Writer, s string) {}, ""},
+		{42, "m.f: int is not a function"},
+		{(func())(nil), "m.f: func() is not a function"},
+		{func(a []int) {}, "m.f: cannot use parameter of type []int"},
+		{func(a string, w io.Writer) {}, "m.f: cannot use parameter of type io.Writer"},
+		{func() (error, int) { return nil, 0 }, "m.f: cannot use result of type error"},
+		{func() map[string]int { return nil }, "m.f: cannot use result of type map[string]int"},
+	}
+	for _, test := range tests {
+		_, err := NewGoFunc("m.f", test.fn)
+		if msg := errString(err); msg != test.err {
+			t.Errorf("NewGoFunc(%T): got error %q, want %q", test.fn, msg, test.err)
+		}
+	}
+}
+
+func TestGoFuncCall(t *testing.T) {
+	var failed = errors.New("failed")
+	var tests = []struct {
+		fn   interface{}
+		args []Value
+		want []Value
+		err  string
+	}{
+		{func(a, b int) int { return a + b }, []Value{IntValue(1), IntValue(2)}, []Value{IntValue(3)}, ""},
+		{func(s ...string) int { return len(s) }, []Value{StringValue("a"), StringValue("b")}, []Value{IntValue(2)}, ""},
+		{func(s ...string) int { return len(s) }, nil, []Value{IntValue(0)}, ""},
+		{func() (int, string) { return 1, "a" }, nil, []Value{IntValue(1), StringValue("a")}, ""},
+		{func() (int, error) { return 1, nil }, nil, []Value{IntValue(1)}, ""},
+		{func() (int, error) { return 0, failed }, nil, nil, "failed"},
+		{func() error { return failed }, nil, nil, "failed"},
+		{func(a int) {}, nil, nil, "wrong number of arguments in call to m.f: have 0, want 1"},
+		{func(a int, s ...string) {}, nil, nil, "wrong number of arguments in call to m.f: have 0, want 1 or more"},
+		{func(a int) {}, []Value{StringValue("x")}, nil, "cannot use x (string) as int value in argument to m.f"},
+		{func() uint64 { return math.MaxUint64 }, nil, nil, "18446744073709551615 overflows int in result of m.f"},
+		{func(w io.Writer, s string) {}, nil, nil, "wrong number of arguments in call to m.f: have 0, want 1"},
+	}
+	for i, test := range tests {
+		f, err := NewGoFunc("m.f", test.fn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := f.Call(test.args)
+		if msg := errString(err); msg != test.err {
+			t.Errorf("%d: got error %q, want %q", i, msg, test.err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%d: got %v, want %v", i, got, test.want)
+		}
+	}
+}
+
+// run runs src with the module m registered as "host" and returns its
+// output.
+func run(t *testing.T, src string, m Module) (string, error) {
+	var l = lexer.NewLexer([]byte(src))
+	var p = parser.NewLexerParse(l)
+	var node = p.Parse()
+	if err := append(l.Diagnostics(), p.Diagnostics()...).Err(); err != nil {
+		t.Fatal(err)
+	}
+	var ev = NewExecVisitor(node)
+	if err := ev.Register("host", m); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	ev.SetOutput(&out)
+	err := ev.Exec()
+	return out.String(), err
+}
+
+func TestHostError(t *testing.T) {
+	var m = Module{
+		"div": func(a, b int) (int, error) {
+			if b == 0 {
+				return 0, errors.New("division by zero")
+			}
+			return a / b, nil
+		},
+		"say": func(w io.Writer, s string) {
+			io.WriteString(w, s+"\n")
+		},
+	}
+	var tests = []struct {
+		src string
+		out string
+		err string
+	}{
+		{"import \"host\"\nif host.div(7, 2) == 3 {\n\thost.say(\"q=3\")\n}\n", "q=3\n", ""},
+		{"import \"host\"\nhost.say(\"before\")\nhost.div(1, 0)\nhost.say(\"after\")\n", "before\n", "division by zero"},
+		{"import \"host\"\nhost.div(1)\n", "", "wrong number of arguments in call to host.div: have 1, want 2"},
+		{"import \"host\"\nhost.div(1, \"2\")\n", "", "cannot use 2 (string) as int value in argument to host.div"},
+	}
+	for _, test := range tests {
+		out, err := run(t, test.src, m)
+		if out != test.out {
+			t.Errorf("%q: got output %q, want %q", test.src, out, test.out)
+		}
+		if msg := errString(err); !strings.Contains(msg, test.err) || (test.err == "") != (err == nil) {
+			t.Errorf("%q: got error %q, want %q", test.src, msg, test.err)
+		}
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
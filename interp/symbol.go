@@ -0,0 +1,108 @@
+package interp
+
+import (
+	"errors"
+	"fmt"
+
+	"myc/ast"
+)
+
+type Symbol struct {
+	name  string
+	value Value
+	t     string // type
+}
+
+func (s *Symbol) String() string {
+	return fmt.Sprintf("(%s:%v:%v)", s.name, s.t, s.value)
+}
+
+func NewSymbolTable(prev *SymbolTable) *SymbolTable {
+	return &SymbolTable{
+		prev: prev,
+		t:    make(map[string]*Symbol),
+	}
+}
+
+// SymbolTable is a scope of the interpreter. Every block, function call
+// and loop iteration gets a new one, prev is the scope around it.
+type SymbolTable struct {
+	prev *SymbolTable
+	t    map[string]*Symbol
+}
+
+func (st *SymbolTable) String() string {
+	return fmt.Sprintf("{%v\n%v}", st.t, st.prev)
+}
+
+// Get returns the symbol of name in the innermost scope that declares it.
+func (st *SymbolTable) Get(name string) *Symbol {
+	if s, ok := st.t[name]; ok {
+		return s
+	}
+	if st.prev == nil {
+		return nil
+	}
+	return st.prev.Get(name)
+}
+
+// SetVar assigns to the variable name of the innermost scope that declares
+// it.
+func (st *SymbolTable) SetVar(name string, value Value) error {
+	if s, ok := st.t[name]; ok {
+		if s.t != "var" {
+			return fmt.Errorf("cannot assign to %s", name)
+		}
+		s.value = value
+		return nil
+	}
+	if st.prev == nil {
+		return fmt.Errorf("undefined: %s", name)
+	}
+	return st.prev.SetVar(name, value)
+}
+
+// DefinedVar declares the variable name in st, it hides a variable of the
+// same name in the scopes around it.
+func (st *SymbolTable) DefinedVar(name string, value Value) error {
+	if _, ok := st.t[name]; ok {
+		return fmt.Errorf("%s redeclared in this block", name)
+	}
+	return st.set(name, "var", value)
+}
+
+// DefinedFunc declares the function fn in st, it is a closure over st.
+func (st *SymbolTable) DefinedFunc(fn ast.ASTFunction) error {
+	if _, ok := st.t[fn.Name.Name]; ok {
+		return fmt.Errorf("%s redeclared in this block", fn.Name.Name)
+	}
+	return st.set(fn.Name.Name, "func", &FuncValue{fn: &fn, env: st})
+}
+
+func (st *SymbolTable) set(name, t string, value Value) error {
+	if s, ok := st.t[name]; ok {
+		if s.t != t {
+			return errors.New("type is not match")
+		}
+		s.t = t
+		return nil
+	}
+	st.t[name] = &Symbol{
+		name:  name,
+		t:     t,
+		value: value,
+	}
+	return nil
+}
+
+func (st *SymbolTable) DefinedOrSetVar(name string, value Value) error {
+	s := st.Get(name)
+	if s == nil {
+		return st.set(name, "var", value)
+	}
+	if s.t != "var" {
+		return fmt.Errorf("cannot assign to %s", name)
+	}
+	s.value = value
+	return nil
+}
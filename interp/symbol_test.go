@@ -0,0 +1,43 @@
+package interp
+
+import "testing"
+
+func TestSymbolTable(t *testing.T) {
+	var outer = NewSymbolTable(nil)
+	if err := outer.DefinedVar("a", IntValue(1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := outer.DefinedVar("a", IntValue(2)); err == nil || err.Error() != "a redeclared in this block" {
+		t.Errorf("DefinedVar of a declared name: got error %v", err)
+	}
+
+	// a variable of an inner scope hides the outer one
+	var inner = NewSymbolTable(outer)
+	if err := inner.DefinedVar("a", IntValue(10)); err != nil {
+		t.Fatal(err)
+	}
+	if err := inner.SetVar("a", IntValue(11)); err != nil {
+		t.Fatal(err)
+	}
+	if v := inner.Get("a").value; v != IntValue(11) {
+		t.Errorf("inner a = %v, want 11", v)
+	}
+	if v := outer.Get("a").value; v != IntValue(1) {
+		t.Errorf("outer a = %v, want 1", v)
+	}
+
+	// without its own variable the inner scope assigns to the outer one
+	var other = NewSymbolTable(outer)
+	if err := other.SetVar("a", IntValue(3)); err != nil {
+		t.Fatal(err)
+	}
+	if v := outer.Get("a").value; v != IntValue(3) {
+		t.Errorf("outer a = %v, want 3", v)
+	}
+	if err := other.SetVar("b", IntValue(1)); err == nil || err.Error() != "undefined: b" {
+		t.Errorf("SetVar of an undeclared name: got error %v", err)
+	}
+	if s := outer.Get("b"); s != nil {
+		t.Errorf("SetVar of an undeclared name declared %v", s)
+	}
+}
@@ -0,0 +1,328 @@
+package interp
+
+import (
+	"fmt"
+	"strconv"
+
+	"myc/ast"
+)
+
+// Kind is the kind of a Value.
+type Kind int
+
+const (
+	KindNil Kind = iota
+	KindInt
+	KindFloat
+	KindString
+	KindBool
+	KindFunc
+)
+
+var kindNames = [...]string{
+	KindNil:    "nil",
+	KindInt:    "int",
+	KindFloat:  "float",
+	KindString: "string",
+	KindBool:   "bool",
+	KindFunc:   "func",
+}
+
+func (k Kind) String() string {
+	if int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// Value is a value of the interpreter.
+type Value interface {
+	Kind() Kind
+	String() string
+}
+
+type NilValue struct{}
+
+func (NilValue) Kind() Kind     { return KindNil }
+func (NilValue) String() string { return "nil" }
+
+type IntValue int
+
+func (IntValue) Kind() Kind       { return KindInt }
+func (v IntValue) String() string { return strconv.Itoa(int(v)) }
+
+type FloatValue float64
+
+func (FloatValue) Kind() Kind { return KindFloat }
+func (v FloatValue) String() string {
+	return strconv.FormatFloat(float64(v), 'g', -1, 64)
+}
+
+type StringValue string
+
+func (StringValue) Kind() Kind       { return KindString }
+func (v StringValue) String() string { return string(v) }
+
+type BoolValue bool
+
+func (BoolValue) Kind() Kind       { return KindBool }
+func (v BoolValue) String() string { return strconv.FormatBool(bool(v)) }
+
+// FuncValue is a myc function with the scope it is declared in, the
+// variables of that scope live as long as the function.
+type FuncValue struct {
+	fn  *ast.ASTFunction
+	env *SymbolTable
+}
+
+func (*FuncValue) Kind() Kind       { return KindFunc }
+func (v *FuncValue) String() string { return "func " + v.fn.Name.Name }
+
+// Truth reports whether v counts as true in a condition. Zero numbers, the
+// empty string, false and nil are false, everything else is true.
+func Truth(v Value) bool {
+	switch v := v.(type) {
+	case nil, NilValue:
+		return false
+	case IntValue:
+		return v != 0
+	case FloatValue:
+		return v != 0
+	case StringValue:
+		return v != ""
+	case BoolValue:
+		return bool(v)
+	}
+	return true
+}
+
+// toFloat converts a number to a float.
+func toFloat(v Value) (float64, bool) {
+	switch v := v.(type) {
+	case IntValue:
+		return float64(v), true
+	case FloatValue:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// Convert converts v to the declared type t of a variable. Ints are
+// widened to floats, other values are kept as they are.
+func Convert(t string, v Value) Value {
+	if n, ok := v.(IntValue); ok && t == "float" {
+		return FloatValue(n)
+	}
+	return v
+}
+
+// Cast converts v to type t for an as expression. Numbers and bools convert
+// to each other, true is 1 and floats are truncated.
+func Cast(t string, v Value) (Value, error) {
+	switch t {
+	case "any":
+		return v, nil
+	case "int":
+		switch v := v.(type) {
+		case IntValue:
+			return v, nil
+		case FloatValue:
+			return IntValue(v), nil
+		case BoolValue:
+			if v {
+				return IntValue(1), nil
+			}
+			return IntValue(0), nil
+		}
+	case "float":
+		if f, ok := toFloat(v); ok {
+			return FloatValue(f), nil
+		}
+		if v, ok := v.(BoolValue); ok {
+			if v {
+				return FloatValue(1), nil
+			}
+			return FloatValue(0), nil
+		}
+	case "bool":
+		if v.Kind() == KindInt || v.Kind() == KindFloat || v.Kind() == KindBool {
+			return BoolValue(Truth(v)), nil
+		}
+	case "string":
+		if v, ok := v.(StringValue); ok {
+			return v, nil
+		}
+	}
+	return nil, fmt.Errorf("cannot convert %v (%v) to %s", v, v.Kind(), t)
+}
+
+// UnaryOp applies a prefix operator to v.
+func UnaryOp(op string, v Value) (Value, error) {
+	switch op {
+	case "+":
+		if v.Kind() == KindInt || v.Kind() == KindFloat {
+			return v, nil
+		}
+	case "-":
+		switch v := v.(type) {
+		case IntValue:
+			return -v, nil
+		case FloatValue:
+			return -v, nil
+		}
+	case "~":
+		if v, ok := v.(IntValue); ok {
+			return ^v, nil
+		}
+	case "!":
+		return BoolValue(!Truth(v)), nil
+	}
+	return nil, fmt.Errorf("invalid operation: operator %s not defined on %v (%v)", op, v, v.Kind())
+}
+
+// BinaryOp applies an arithmetic or comparison operator to l and r. Ints
+// and floats can be mixed, the result is then a float.
+func BinaryOp(op string, l, r Value) (Value, error) {
+	switch op {
+	case "==":
+		return BoolValue(Equal(l, r)), nil
+	case "!=":
+		return BoolValue(!Equal(l, r)), nil
+	case "<", "<=", ">", ">=":
+		c, err := Compare(l, r)
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case "<":
+			return BoolValue(c < 0), nil
+		case "<=":
+			return BoolValue(c <= 0), nil
+		case ">":
+			return BoolValue(c > 0), nil
+		}
+		return BoolValue(c >= 0), nil
+	}
+
+	switch l := l.(type) {
+	case IntValue:
+		if r, ok := r.(IntValue); ok {
+			return intOp(op, l, r)
+		}
+	case StringValue:
+		if r, ok := r.(StringValue); ok && op == "+" {
+			return l + r, nil
+		}
+	}
+	lf, lok := toFloat(l)
+	rf, rok := toFloat(r)
+	if lok && rok {
+		return floatOp(op, lf, rf)
+	}
+	return nil, fmt.Errorf("invalid operation: %v %s %v (mismatched types %v and %v)", l, op, r, l.Kind(), r.Kind())
+}
+
+func intOp(op string, l, r IntValue) (Value, error) {
+	switch op {
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/", "%":
+		if r == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		if op == "/" {
+			return l / r, nil
+		}
+		return l % r, nil
+	case "&":
+		return l & r, nil
+	case "|":
+		return l | r, nil
+	case "^":
+		return l ^ r, nil
+	case "<<", ">>":
+		if r < 0 {
+			return nil, fmt.Errorf("negative shift count %d", r)
+		}
+		if op == "<<" {
+			return l << uint(r), nil
+		}
+		return l >> uint(r), nil
+	}
+	return nil, fmt.Errorf("invalid operation: operator %s not defined on int", op)
+}
+
+func floatOp(op string, l, r float64) (Value, error) {
+	switch op {
+	case "+":
+		return FloatValue(l + r), nil
+	case "-":
+		return FloatValue(l - r), nil
+	case "*":
+		return FloatValue(l * r), nil
+	case "/":
+		return FloatValue(l / r), nil
+	}
+	return nil, fmt.Errorf("invalid operation: operator %s not defined on float", op)
+}
+
+// Equal reports whether l and r are the same value. Numbers are compared by
+// value, so 1 == 1.0.
+func Equal(l, r Value) bool {
+	lf, lok := toFloat(l)
+	rf, rok := toFloat(r)
+	if lok && rok {
+		return lf == rf
+	}
+	if l.Kind() != r.Kind() {
+		return false
+	}
+	switch l := l.(type) {
+	case *FuncValue:
+		r, ok := r.(*FuncValue)
+		return ok && l.fn == r.fn
+	}
+	return l == r
+}
+
+// Compare orders numbers and strings, it returns -1, 0 or +1.
+func Compare(l, r Value) (int, error) {
+	if l, ok := l.(IntValue); ok {
+		if r, ok := r.(IntValue); ok {
+			switch {
+			case l < r:
+				return -1, nil
+			case l > r:
+				return 1, nil
+			}
+			return 0, nil
+		}
+	}
+	lf, lok := toFloat(l)
+	rf, rok := toFloat(r)
+	if lok && rok {
+		switch {
+		case lf < rf:
+			return -1, nil
+		case lf > rf:
+			return 1, nil
+		}
+		return 0, nil
+	}
+	if l, ok := l.(StringValue); ok {
+		if r, ok := r.(StringValue); ok {
+			switch {
+			case l < r:
+				return -1, nil
+			case l > r:
+				return 1, nil
+			}
+			return 0, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid operation: cannot compare %v (%v) and %v (%v)", l, l.Kind(), r, r.Kind())
+}
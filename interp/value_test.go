@@ -0,0 +1,184 @@
+package interp
+
+import "testing"
+
+func TestTruth(t *testing.T) {
+	var tests = []struct {
+		v    Value
+		want bool
+	}{
+		{IntValue(0), false},
+		{IntValue(-1), true},
+		{FloatValue(0), false},
+		{FloatValue(0.5), true},
+		{StringValue(""), false},
+		{StringValue("a"), true},
+		{BoolValue(false), false},
+		{BoolValue(true), true},
+		{NilValue{}, false},
+		{nil, false},
+	}
+	for _, test := range tests {
+		if got := Truth(test.v); got != test.want {
+			t.Errorf("Truth(%#v) = %v, want %v", test.v, got, test.want)
+		}
+	}
+}
+
+func TestBinaryOp(t *testing.T) {
+	var tests = []struct {
+		l    Value
+		op   string
+		r    Value
+		want Value
+		err  string
+	}{
+		{IntValue(7), "+", IntValue(2), IntValue(9), ""},
+		{IntValue(7), "-", IntValue(2), IntValue(5), ""},
+		{IntValue(7), "*", IntValue(2), IntValue(14), ""},
+		{IntValue(7), "/", IntValue(2), IntValue(3), ""},
+		{IntValue(-7), "/", IntValue(2), IntValue(-3), ""},
+		{IntValue(7), "/", IntValue(0), nil, "division by zero"},
+		{IntValue(1), "+", FloatValue(0.5), FloatValue(1.5), ""},
+		{FloatValue(7), "/", IntValue(2), FloatValue(3.5), ""},
+		{FloatValue(1), "/", FloatValue(0), FloatValue(posInf()), ""},
+		{StringValue("a"), "+", StringValue("b"), StringValue("ab"), ""},
+		{StringValue("a"), "*", StringValue("b"), nil, "invalid operation: a * b (mismatched types string and string)"},
+		{StringValue("a"), "+", IntValue(1), nil, "invalid operation: a + 1 (mismatched types string and int)"},
+		{BoolValue(true), "+", BoolValue(true), nil, "invalid operation: true + true (mismatched types bool and bool)"},
+
+		{IntValue(1), "==", FloatValue(1), BoolValue(true), ""},
+		{IntValue(1), "!=", IntValue(2), BoolValue(true), ""},
+		{StringValue("a"), "==", IntValue(1), BoolValue(false), ""},
+		{NilValue{}, "==", NilValue{}, BoolValue(true), ""},
+		{IntValue(1), "<", IntValue(2), BoolValue(true), ""},
+		{IntValue(2), "<=", FloatValue(1.5), BoolValue(false), ""},
+		{StringValue("b"), ">", StringValue("a"), BoolValue(true), ""},
+		{StringValue("a"), ">=", StringValue("a"), BoolValue(true), ""},
+		{StringValue("a"), "<", IntValue(1), nil, "invalid operation: cannot compare a (string) and 1 (int)"},
+		{BoolValue(false), "<", BoolValue(true), nil, "invalid operation: cannot compare false (bool) and true (bool)"},
+	}
+	for _, test := range tests {
+		v, err := BinaryOp(test.op, test.l, test.r)
+		if err != nil || test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%v %s %v: got error %v, want %q", test.l, test.op, test.r, err, test.err)
+			}
+			continue
+		}
+		if v != test.want {
+			t.Errorf("%v %s %v = %#v, want %#v", test.l, test.op, test.r, v, test.want)
+		}
+	}
+}
+
+func TestUnaryOp(t *testing.T) {
+	var tests = []struct {
+		op   string
+		v    Value
+		want Value
+		err  string
+	}{
+		{"-", IntValue(2), IntValue(-2), ""},
+		{"-", FloatValue(0.5), FloatValue(-0.5), ""},
+		{"+", IntValue(2), IntValue(2), ""},
+		{"+", FloatValue(0.5), FloatValue(0.5), ""},
+		{"-", StringValue("a"), nil, "invalid operation: operator - not defined on a (string)"},
+		{"+", BoolValue(true), nil, "invalid operation: operator + not defined on true (bool)"},
+	}
+	for _, test := range tests {
+		v, err := UnaryOp(test.op, test.v)
+		if err != nil || test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%s%v: got error %v, want %q", test.op, test.v, err, test.err)
+			}
+			continue
+		}
+		if v != test.want {
+			t.Errorf("%s%v = %#v, want %#v", test.op, test.v, v, test.want)
+		}
+	}
+}
+
+func posInf() float64 {
+	var zero float64
+	return 1 / zero
+}
+
+func TestIntOp(t *testing.T) {
+	var tests = []struct {
+		l    IntValue
+		op   string
+		r    IntValue
+		want Value
+		err  string
+	}{
+		{7, "%", 3, IntValue(1), ""},
+		{-7, "%", 3, IntValue(-1), ""},
+		{7, "%", 0, nil, "division by zero"},
+		{6, "&", 3, IntValue(2), ""},
+		{6, "|", 3, IntValue(7), ""},
+		{6, "^", 3, IntValue(5), ""},
+		{1, "<<", 40, IntValue(1 << 40), ""},
+		{-256, ">>", 2, IntValue(-64), ""},
+		{1, "<<", -1, nil, "negative shift count -1"},
+	}
+	for _, test := range tests {
+		v, err := BinaryOp(test.op, test.l, test.r)
+		if err != nil || test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%v %s %v: got error %v, want %q", test.l, test.op, test.r, err, test.err)
+			}
+			continue
+		}
+		if v != test.want {
+			t.Errorf("%v %s %v = %#v, want %#v", test.l, test.op, test.r, v, test.want)
+		}
+	}
+	for _, op := range []string{"%", "&", "|", "^", "<<", ">>"} {
+		var want = "invalid operation: operator " + op + " not defined on float"
+		if _, err := BinaryOp(op, FloatValue(1), IntValue(1)); err == nil || err.Error() != want {
+			t.Errorf("1.0 %s 1: got error %v, want %q", op, err, want)
+		}
+	}
+	if v, err := UnaryOp("~", IntValue(5)); v != IntValue(-6) || err != nil {
+		t.Errorf("~5 = %v, %v", v, err)
+	}
+	if _, err := UnaryOp("~", FloatValue(5)); err == nil {
+		t.Errorf("~5.0 is not an error")
+	}
+}
+
+func TestCast(t *testing.T) {
+	var tests = []struct {
+		typ  string
+		v    Value
+		want Value
+		err  string
+	}{
+		{"int", IntValue(3), IntValue(3), ""},
+		{"int", FloatValue(-2.7), IntValue(-2), ""},
+		{"int", BoolValue(true), IntValue(1), ""},
+		{"float", IntValue(3), FloatValue(3), ""},
+		{"float", BoolValue(false), FloatValue(0), ""},
+		{"bool", IntValue(2), BoolValue(true), ""},
+		{"bool", FloatValue(0), BoolValue(false), ""},
+		{"string", StringValue("s"), StringValue("s"), ""},
+		{"any", StringValue("s"), StringValue("s"), ""},
+		{"int", StringValue("1"), nil, "cannot convert 1 (string) to int"},
+		{"bool", StringValue("s"), nil, "cannot convert s (string) to bool"},
+		{"string", IntValue(1), nil, "cannot convert 1 (int) to string"},
+	}
+	for _, test := range tests {
+		v, err := Cast(test.typ, test.v)
+		if err != nil || test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%v as %s: got error %v, want %q", test.v, test.typ, err, test.err)
+			}
+			continue
+		}
+		if v != test.want {
+			t.Errorf("%v as %s = %#v, want %#v", test.v, test.typ, v, test.want)
+		}
+	}
+}
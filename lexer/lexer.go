@@ -0,0 +1,634 @@
+// Package lexer turns myc source into tokens.
+package lexer
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"myc/diag"
+	"myc/token"
+	"myc/trace"
+)
+
+// punctuation ends an identifier
+const punctuation = " \\\t\r\n\"';:`~!@#$%^&*()+-=|{}[]<>,./?"
+
+func NewLexer(b []byte) *Lexer {
+	return NewFileLexer(token.NewFileSet().AddFile("", len(b)), b)
+}
+
+// NewFileLexer returns a lexer for b which is the content of file.
+func NewFileLexer(file *token.File, b []byte) *Lexer {
+	return NewReaderLexer(file, bytes.NewReader(b))
+}
+
+// NewReaderLexer returns a lexer reading the content of file from r. It
+// only reads as far as the tokens asked for, file grows as it is read.
+func NewReaderLexer(file *token.File, r io.Reader) *Lexer {
+	return &Lexer{r: bufio.NewReader(r), file: file, line: 1}
+}
+
+type Lexer struct {
+	r       *bufio.Reader
+	err     error // the read error that ended the input
+	file    *token.File
+	pos     int
+	line    int
+	offset  int  // runes consumed on the current line
+	newline bool // the last byte was a newline
+
+	// start of the current token and the bytes read since
+	start       int
+	startLine   int
+	startOffset int
+	text        []byte
+
+	comments     []token.Comment // comments for the next token
+	lineHasToken bool            // a token other than a newline is on the current line
+
+	diags diag.Diagnostics
+	trace trace.Tracer
+}
+
+// SetTracer makes the lexer report every token it reads to t.
+func (l *Lexer) SetTracer(t trace.Tracer) {
+	l.trace = t
+}
+
+// Diagnostics returns the errors found in the tokens read so far.
+func (l *Lexer) Diagnostics() diag.Diagnostics {
+	return l.diags
+}
+
+// token returns a token of type t starting at the current token start.
+func (l *Lexer) token(t token.TokenType, v string) *token.Token {
+	var tok = &token.Token{
+		Type:   t,
+		Value:  v,
+		Line:   l.startLine,
+		Offset: l.startOffset,
+		Pos:    l.file.Pos(l.start),
+		End:    l.file.Pos(l.pos),
+	}
+	if t == token.TokenString {
+		tok.Lit = string(l.text)
+	}
+	// comments on their own lines wait for the next token
+	if t != token.TokenEnter || l.lineHasToken {
+		tok.Lead, l.comments = l.comments, nil
+	}
+	l.lineHasToken = t != token.TokenEnter
+	if l.trace != nil {
+		l.trace.Trace(trace.Event{Kind: trace.Lex, Token: tok})
+	}
+	return tok
+}
+
+func (l *Lexer) errorf(format string, args ...interface{}) {
+	l.errorfAt(l.start, format, args...)
+}
+
+// errorfAt reports an error at offset in the source.
+func (l *Lexer) errorfAt(offset int, format string, args ...interface{}) {
+	l.diags = append(l.diags, diag.Errorf(l.file.Pos(offset), format, args...))
+}
+
+// LexerToken reads all tokens up to and including EOF.
+func (l *Lexer) LexerToken() []*token.Token {
+	var t []*token.Token
+	var v = l.GetNextToken()
+	for ; v.Type != token.TokenEOF; v = l.GetNextToken() {
+		t = append(t, v)
+	}
+	t = append(t, v)
+	return t
+}
+
+func (l *Lexer) Advance() byte {
+	b, err := l.r.ReadByte()
+	if err != nil {
+		l.fail(err)
+		return 0
+	}
+	if l.newline {
+		l.file.Grow(l.pos + 1)
+		l.file.AddLine(l.pos)
+		l.newline = false
+	}
+	l.text = append(l.text, b)
+	if utf8.RuneStart(b) {
+		l.offset++
+	} else {
+		// a column is a rune, not a byte
+		l.file.AddCont(l.pos)
+	}
+	l.pos++
+	l.file.Grow(l.pos)
+	if b == '\n' {
+		l.line++
+		l.offset = 0
+		l.newline = true
+	}
+	return b
+}
+
+// fail ends the input at a read error, io.EOF is not an error.
+func (l *Lexer) fail(err error) {
+	if l.err == nil && err != io.EOF {
+		l.err = err
+		l.errorfAt(l.pos, "%v", err)
+	}
+}
+
+// AdvanceUntil do not contain c
+func (l *Lexer) AdvanceUntil(c byte) int {
+	var n int
+	for !l.eof() && l.Peek() != c {
+		l.Advance()
+		n++
+	}
+	return n
+}
+
+// eof reports whether all input has been read.
+func (l *Lexer) eof() bool {
+	if _, err := l.r.Peek(1); err != nil {
+		l.fail(err)
+		return true
+	}
+	return false
+}
+
+func (l *Lexer) Peek() byte {
+	return l.peekAt(0)
+}
+
+// peekAt returns the byte n bytes after the next one.
+func (l *Lexer) peekAt(n int) byte {
+	b, err := l.r.Peek(n + 1)
+	if len(b) <= n {
+		if n == 0 {
+			l.fail(err)
+		}
+		return 0
+	}
+	return b[n]
+}
+
+// bom is the UTF-8 byte order mark, it is ignored at the start of a file.
+var bom = []byte{0xEF, 0xBB, 0xBF}
+
+func (l *Lexer) GetNextToken() *token.Token {
+	if b, _ := l.r.Peek(len(bom)); l.pos == 0 && bytes.Equal(b, bom) {
+		for range bom {
+			l.Advance()
+		}
+	}
+	l.start, l.startLine, l.startOffset = l.pos, l.line, l.offset+1
+	l.text = l.text[:0]
+	var c = l.Advance()
+	switch c {
+	case 0: // eof
+		return l.token(token.TokenEOF, "EOF")
+	case ' ', '\t': // white spec
+		return l.GetNextToken()
+	case '\r', '\n':
+		c = l.Peek()
+		for c != 0 && (c == ' ' || c == '\t' || c == '\r' || c == '\n') {
+			l.Advance()
+			c = l.Peek()
+		}
+		return l.token(token.TokenEnter, "ENTER")
+	case '+':
+		if l.Peek() == '=' {
+			return l.token(token.TokenAssign, string([]byte{c, l.Advance()}))
+		}
+		return l.token(token.TokenPlus, "+")
+	case '-':
+		// if l.Peek() == '-' {
+		// 	l.AdvanceUntil('\n')
+		// 	return l.GetNextToken()
+		// }
+		if l.Peek() == '=' {
+			return l.token(token.TokenAssign, string([]byte{c, l.Advance()}))
+		}
+		return l.token(token.TokenMinus, "-")
+	case '*':
+		if l.Peek() == '=' {
+			return l.token(token.TokenAssign, string([]byte{c, l.Advance()}))
+		}
+		return l.token(token.TokenMul, "*")
+	case '/':
+		if c := l.Peek(); c == '/' || c == '*' {
+			l.comment()
+			return l.GetNextToken()
+		}
+		if l.Peek() == '=' {
+			return l.token(token.TokenAssign, string([]byte{c, l.Advance()}))
+		}
+		return l.token(token.TokenDiv, "/")
+	case '1', '2', '3', '4', '5', '6', '7', '8', '9', '0':
+		return l.token(token.TokenNumber, l.number(c))
+	case '"': // String
+		return l.token(token.TokenString, l.quoted())
+	case '`': // raw string
+		return l.token(token.TokenString, l.raw())
+	case '(':
+		return l.token(token.TokenLParen, "(")
+	case ')':
+		return l.token(token.TokenRParen, ")")
+	case '{':
+		return l.token(token.TokenLBrace, "{")
+	case '}':
+		return l.token(token.TokenRBrace, "}")
+	case '[':
+		return l.token(token.TokenLBracket, "[")
+	case ']':
+		return l.token(token.TokenRBracket, "]")
+	case '=':
+		if l.Peek() == '=' {
+			l.Advance()
+			return l.token(token.TokenCompare, "==")
+		}
+		return l.token(token.TokenAssign, "=")
+	case ',':
+		return l.token(token.TokenComma, ",")
+	case '.':
+		if l.Peek() == '.' {
+			l.Advance()
+			return l.token(token.TokenRange, "..")
+		}
+		return l.token(token.TokenDot, ".")
+	case ';':
+		return l.token(token.TokenSemicolon, ";")
+	case ':':
+		return l.token(token.TokenColon, ":")
+	case '<', '>':
+		if l.Peek() == c { // shift
+			l.Advance()
+			if l.Peek() == '=' {
+				l.Advance()
+				return l.token(token.TokenAssign, string([]byte{c, c, '='}))
+			}
+			return l.token(token.TokenOpBit, string([]byte{c, c}))
+		}
+		if l.Peek() == '=' {
+			return l.token(token.TokenCompare, string([]byte{c, l.Advance()}))
+		}
+		return l.token(token.TokenCompare, string(c))
+	case '%':
+		if l.Peek() == '=' {
+			return l.token(token.TokenAssign, string([]byte{c, l.Advance()}))
+		}
+		return l.token(token.TokenMod, "%")
+	case '&':
+		if l.Peek() == '&' {
+			l.Advance()
+			return l.token(token.TokenAnd, "&&")
+		}
+		if l.Peek() == '=' {
+			return l.token(token.TokenAssign, string([]byte{c, l.Advance()}))
+		}
+		return l.token(token.TokenOpAnd, "&")
+	case '|':
+		if l.Peek() == '|' {
+			l.Advance()
+			return l.token(token.TokenOr, "||")
+		}
+		if l.Peek() == '=' {
+			return l.token(token.TokenAssign, string([]byte{c, l.Advance()}))
+		}
+		return l.token(token.TokenOpBit, "|")
+	case '^':
+		if l.Peek() == '=' {
+			return l.token(token.TokenAssign, string([]byte{c, l.Advance()}))
+		}
+		return l.token(token.TokenOpBit, "^")
+	case '~':
+		return l.token(token.TokenUnaryOp, "~")
+	case '!':
+		if l.Peek() == '=' {
+			l.Advance()
+			return l.token(token.TokenCompare, "!=")
+		}
+		return l.token(token.TokenNot, "!")
+	}
+
+	if strings.IndexByte(punctuation, c) >= 0 {
+		l.errorf("unexpected character %q", c)
+		return l.GetNextToken()
+	}
+
+	// ID
+	if r, ok := l.rest(c); !ok {
+		l.errorf("invalid UTF-8 encoding")
+		for !utf8.RuneStart(l.Peek()) {
+			l.Advance()
+		}
+		return l.GetNextToken()
+	} else if r != '_' && !unicode.IsLetter(r) {
+		l.errorf("unexpected character %q", r)
+		return l.GetNextToken()
+	}
+	for {
+		r, size := l.peekRune()
+		if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			break
+		}
+		for ; size > 0; size-- {
+			l.Advance()
+		}
+	}
+	if t, ok := token.KeyWords[string(l.text)]; ok {
+		return l.token(t.Type, t.Value)
+	}
+	return l.token(token.TokenID, string(l.text))
+}
+
+// comment reads the rest of a comment that started with '/' and keeps it
+// for the next token. Block comments can be nested.
+func (l *Lexer) comment() {
+	if l.Advance() == '/' {
+		l.AdvanceUntil('\n')
+	} else {
+		for depth := 1; depth > 0; {
+			if l.eof() {
+				l.errorf("comment not terminated")
+				break
+			}
+			switch c := l.Advance(); {
+			case c == '/' && l.Peek() == '*':
+				l.Advance()
+				depth++
+			case c == '*' && l.Peek() == '/':
+				l.Advance()
+				depth--
+			}
+		}
+	}
+	l.comments = append(l.comments, token.Comment{
+		Text: strings.TrimSuffix(string(l.text), "\r"),
+		Line: l.startLine,
+		Pos:  l.file.Pos(l.start),
+		End:  l.file.Pos(l.pos),
+	})
+}
+
+// rest reads the rest of the UTF-8 encoded rune that starts with c and
+// returns it. ok is false if the encoding is invalid.
+func (l *Lexer) rest(c byte) (r rune, ok bool) {
+	if c < utf8.RuneSelf {
+		return rune(c), true
+	}
+	var b, _ = l.r.Peek(utf8.UTFMax - 1)
+	r, size := utf8.DecodeRune(append([]byte{c}, b...))
+	for i := 1; i < size; i++ {
+		l.Advance()
+	}
+	return r, size > 1
+}
+
+// char appends the character of a string literal that starts with c to s.
+func (l *Lexer) char(s []byte, c byte) []byte {
+	var start = l.pos - 1
+	if _, ok := l.rest(c); !ok {
+		l.errorfAt(start, "invalid UTF-8 encoding")
+	}
+	return append(s, l.text[start-l.start:]...)
+}
+
+// peekRune returns the next rune and its size without reading it. It is
+// utf8.RuneError at the end or when the encoding is invalid.
+func (l *Lexer) peekRune() (rune, int) {
+	var b, _ = l.r.Peek(utf8.UTFMax)
+	if len(b) == 0 {
+		return utf8.RuneError, 0
+	}
+	return utf8.DecodeRune(b)
+}
+
+// quoted reads the rest of an interpreted string literal and returns its value.
+func (l *Lexer) quoted() string {
+	var s []byte
+	for {
+		if l.eof() || l.Peek() == '\n' {
+			l.errorf("string literal not terminated")
+			return string(s)
+		}
+		switch c := l.Advance(); c {
+		case '"':
+			return string(s)
+		case '\\':
+			s = l.escape(s)
+		default:
+			s = l.char(s, c)
+		}
+	}
+}
+
+// escape reads an escape sequence after its backslash and appends its value to s.
+func (l *Lexer) escape(s []byte) []byte {
+	var start = l.pos - 1
+	if l.eof() || l.Peek() == '\n' {
+		return s // reported as an unterminated string
+	}
+	switch c := l.Advance(); c {
+	case 'n':
+		return append(s, '\n')
+	case 't':
+		return append(s, '\t')
+	case 'r':
+		return append(s, '\r')
+	case '0':
+		return append(s, 0)
+	case '\\', '"', '\'':
+		return append(s, c)
+	case 'x':
+		var v, n = l.hex(2)
+		if n != 2 {
+			l.errorfAt(start, "\\x must be followed by two hexadecimal digits")
+			return s
+		}
+		return append(s, byte(v))
+	case 'u':
+		if l.Peek() != '{' {
+			l.errorfAt(start, "\\u must be followed by {hexadecimal digits}")
+			return s
+		}
+		l.Advance()
+		var v, n = l.hex(6)
+		if n == 0 || l.Peek() != '}' {
+			l.errorfAt(start, "\\u{...} must contain 1 to 6 hexadecimal digits")
+			l.AdvanceUntil('}')
+			if l.Peek() == '}' {
+				l.Advance()
+			}
+			return s
+		}
+		l.Advance()
+		if v > utf8.MaxRune || v >= 0xD800 && v <= 0xDFFF {
+			l.errorfAt(start, "invalid Unicode code point U+%04X", v)
+			return s
+		}
+		return append(s, string(rune(v))...)
+	default:
+		l.errorfAt(start, "unknown escape sequence \\%c", c)
+		return s
+	}
+}
+
+// hex reads up to max hexadecimal digits and returns their value and count.
+func (l *Lexer) hex(max int) (v, n int) {
+	for ; n < max; n++ {
+		var c = l.Peek()
+		switch {
+		case '0' <= c && c <= '9':
+			v = v*16 + int(c-'0')
+		case 'a' <= c && c <= 'f':
+			v = v*16 + int(c-'a'+10)
+		case 'A' <= c && c <= 'F':
+			v = v*16 + int(c-'A'+10)
+		default:
+			return v, n
+		}
+		l.Advance()
+	}
+	return v, n
+}
+
+// raw reads the rest of a raw string literal, which may span lines and has
+// no escapes, and returns its value. Carriage returns are dropped.
+func (l *Lexer) raw() string {
+	var s []byte
+	for {
+		if l.eof() {
+			l.errorf("raw string literal not terminated")
+			return string(s)
+		}
+		switch c := l.Advance(); c {
+		case '`':
+			return string(s)
+		case '\r':
+		default:
+			s = l.char(s, c)
+		}
+	}
+}
+
+// number reads the rest of a number literal starting with c and returns its
+// text. Invalid literals are reported and returned as they are.
+//
+//	number   : decimal | '0' ('x' | 'b' | 'o') digits
+//	decimal  : digits ('.' digits?)? exponent? ('i' | 'f')?
+//	exponent : ('e' | 'E') ('+' | '-')? digits
+//
+// Digits may be separated by single underscores.
+func (l *Lexer) number(c byte) string {
+	// only the first error in a literal is kept
+	defer func(n int) {
+		if len(l.diags) > n+1 {
+			l.diags = l.diags[:n+1]
+		}
+	}(len(l.diags))
+	var base = 10
+	if c == '0' {
+		switch l.Peek() {
+		case 'x', 'X':
+			base = 16
+		case 'b', 'B':
+			base = 2
+		case 'o', 'O':
+			base = 8
+		}
+	}
+	if base != 10 {
+		l.Advance()
+		if !l.digits(base, true) {
+			l.errorf("%s literal has no digits", baseNames[base])
+		}
+	} else {
+		l.digits(10, true)
+		var float bool
+		// 0..10 is a range, not a number
+		if l.Peek() == '.' && l.peekAt(1) != '.' {
+			l.Advance()
+			l.digits(10, false)
+			float = true
+		}
+		if c := l.Peek(); c == 'e' || c == 'E' {
+			l.Advance()
+			if c := l.Peek(); c == '+' || c == '-' {
+				l.Advance()
+			}
+			if !l.digits(10, false) {
+				l.errorf("exponent has no digits")
+			}
+			float = true
+		}
+		if !float && c == '0' && len(l.text) > 1 {
+			l.errorf("invalid number %s: use 0o for an octal literal", l.text)
+		}
+		if c := l.Peek(); (c == 'i' || c == 'f') && !isIdent(l.peekAt(1)) {
+			l.Advance()
+		}
+	}
+	// 1.2.3, 0xzz and 12ab are single invalid literals
+	if c := l.Peek(); isIdent(c) || c == '.' && l.peekAt(1) != '.' {
+		for c := l.Peek(); isIdent(c) || c == '.' && l.peekAt(1) != '.'; c = l.Peek() {
+			l.Advance()
+		}
+		l.errorf("invalid number %s", l.text)
+	}
+	return string(l.text)
+}
+
+var baseNames = map[int]string{2: "binary", 8: "octal", 10: "decimal", 16: "hexadecimal"}
+
+// digits reads digits of base separated by single underscores and reports
+// whether it read any. prev reports whether an underscore may come first.
+func (l *Lexer) digits(base int, prev bool) bool {
+	var ok bool
+	for {
+		var c = l.Peek()
+		if c == '_' {
+			if !prev || digitValue(l.peekAt(1)) >= base {
+				l.errorf("'_' must separate successive digits")
+			}
+			l.Advance()
+			prev = false
+			continue
+		}
+		var d = digitValue(c)
+		if d >= base && (base == 10 || d >= 16) {
+			return ok
+		}
+		if d >= base {
+			l.errorf("invalid digit %q in %s literal", c, baseNames[base])
+		}
+		l.Advance()
+		ok, prev = true, true
+	}
+}
+
+// digitValue returns the value of the hexadecimal digit c, or 16 if c is
+// not one.
+func digitValue(c byte) int {
+	switch {
+	case '0' <= c && c <= '9':
+		return int(c - '0')
+	case 'a' <= c && c <= 'f':
+		return int(c - 'a' + 10)
+	case 'A' <= c && c <= 'F':
+		return int(c - 'A' + 10)
+	}
+	return 16
+}
+
+// isIdent reports whether c can appear in an identifier.
+func isIdent(c byte) bool {
+	return c != 0 && strings.IndexByte(punctuation, c) < 0
+}
@@ -0,0 +1,301 @@
+package lexer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"myc/token"
+)
+
+// firstToken reads the first token of src and returns it with the first
+// error.
+func firstToken(src string) (*token.Token, string) {
+	var l = NewLexer([]byte(src))
+	var t = l.GetNextToken()
+	if len(l.diags) > 0 {
+		return t, l.diags[0].Message
+	}
+	return t, ""
+}
+
+func TestString(t *testing.T) {
+	var tests = []struct {
+		src   string
+		value string
+		err   string
+	}{
+		{`"abc"`, "abc", ""},
+		{`""`, "", ""},
+		{`"a\nb\tc\rd"`, "a\nb\tc\rd", ""},
+		{`"\\ \" \' \0"`, "\\ \" ' \x00", ""},
+		{`"\x41\x7a"`, "Az", ""},
+		{`"\u{48}\u{e9}\u{1F600}"`, "Hé😀", ""},
+		{`"héllo, 世界"`, "héllo, 世界", ""},
+		{`"\x4"`, "", `\x must be followed by two hexadecimal digits`},
+		{`"\u48"`, "48", `\u must be followed by {hexadecimal digits}`},
+		{`"\u{}"`, "", `\u{...} must contain 1 to 6 hexadecimal digits`},
+		{`"\u{1234567}"`, "", `\u{...} must contain 1 to 6 hexadecimal digits`},
+		{`"\u{D800}"`, "", "invalid Unicode code point U+D800"},
+		{`"\u{110000}"`, "", "invalid Unicode code point U+110000"},
+		{`"\q"`, "", `unknown escape sequence \q`},
+		{`"abc`, "abc", "string literal not terminated"},
+		{"\"abc\ndef\"", "abc", "string literal not terminated"},
+		{`"abc\`, "abc", "string literal not terminated"},
+		{"\"\xff\"", "\xff", "invalid UTF-8 encoding"},
+
+		{"`abc`", "abc", ""},
+		{"`a\\nb`", `a\nb`, ""},
+		{"`a\"b`", `a"b`, ""},
+		{"`a\nb`", "a\nb", ""},
+		{"`a\r\nb`", "a\nb", ""},
+		{"`abc", "abc", "raw string literal not terminated"},
+	}
+	for _, test := range tests {
+		tok, err := firstToken(test.src)
+		if tok.Type != token.TokenString {
+			t.Errorf("%s: got token %v, want string", test.src, tok.Type)
+			continue
+		}
+		if tok.Value != test.value {
+			t.Errorf("%s: got value %q, want %q", test.src, tok.Value, test.value)
+		}
+		if err != test.err {
+			t.Errorf("%s: got error %q, want %q", test.src, err, test.err)
+		}
+	}
+}
+
+func TestStringLit(t *testing.T) {
+	// Lit keeps the source text for the formatter
+	for _, src := range []string{`"a\tb"`, `"\u{48}i"`, "`a\\nb`"} {
+		tok, _ := firstToken(src + " x")
+		if tok.Lit != src {
+			t.Errorf("%s: got Lit %q", src, tok.Lit)
+		}
+	}
+}
+
+func TestNumber(t *testing.T) {
+	var tests = []struct {
+		src  string
+		text string
+		err  string
+	}{
+		{"0", "0", ""},
+		{"42", "42", ""},
+		{"1_000_000", "1_000_000", ""},
+		{"3.14", "3.14", ""},
+		{"1.", "1.", ""},
+		{"1e10", "1e10", ""},
+		{"2.5E-3", "2.5E-3", ""},
+		{"1.5f", "1.5f", ""},
+		{"7i", "7i", ""},
+		{"0x1F", "0x1F", ""},
+		{"0XaB_cd", "0XaB_cd", ""},
+		{"0x_1", "0x_1", ""},
+		{"0b1010", "0b1010", ""},
+		{"0o17", "0o17", ""},
+
+		{"0x", "0x", "hexadecimal literal has no digits"},
+		{"0b", "0b", "binary literal has no digits"},
+		{"0o", "0o", "octal literal has no digits"},
+		{"0xg", "0xg", "hexadecimal literal has no digits"},
+		{"0b102", "0b102", `invalid digit '2' in binary literal`},
+		{"0o8", "0o8", `invalid digit '8' in octal literal`},
+		{"017", "017", "invalid number 017: use 0o for an octal literal"},
+		{"1__0", "1__0", "'_' must separate successive digits"},
+		{"1_", "1_", "'_' must separate successive digits"},
+		{"1._5", "1._5", "'_' must separate successive digits"},
+		{"1e", "1e", "exponent has no digits"},
+		{"1e+", "1e+", "exponent has no digits"},
+		{"1.2.3", "1.2.3", "invalid number 1.2.3"},
+		{"12ab", "12ab", "invalid number 12ab"},
+	}
+	for _, test := range tests {
+		tok, err := firstToken(test.src)
+		if tok.Type != token.TokenNumber {
+			t.Errorf("%s: got token %v, want number", test.src, tok.Type)
+			continue
+		}
+		if tok.Value != test.text {
+			t.Errorf("%s: got %q, want %q", test.src, tok.Value, test.text)
+		}
+		if err != test.err {
+			t.Errorf("%s: got error %q, want %q", test.src, err, test.err)
+		}
+	}
+}
+
+func TestNumberRange(t *testing.T) {
+	// 0..10 is a range, not the float 0.
+	var l = NewLexer([]byte("0..10"))
+	var got []string
+	for tok := l.GetNextToken(); tok.Type != token.TokenEOF; tok = l.GetNextToken() {
+		got = append(got, tok.Value)
+	}
+	if len(got) != 3 || got[0] != "0" || got[2] != "10" || len(l.diags) > 0 {
+		t.Errorf("got %q, %v", got, l.diags)
+	}
+}
+
+func TestOperatorTokens(t *testing.T) {
+	var tests = []struct {
+		src string
+		typ token.TokenType
+	}{
+		{"%", token.TokenMod},
+		{"&", token.TokenOpAnd},
+		{"|", token.TokenOpBit},
+		{"^", token.TokenOpBit},
+		{"<<", token.TokenOpBit},
+		{">>", token.TokenOpBit},
+		{"~", token.TokenUnaryOp},
+		{"&&", token.TokenAnd},
+		{"||", token.TokenOr},
+		{"<", token.TokenCompare},
+		{">=", token.TokenCompare},
+		{"%=", token.TokenAssign},
+		{"&=", token.TokenAssign},
+		{"|=", token.TokenAssign},
+		{"^=", token.TokenAssign},
+		{"<<=", token.TokenAssign},
+		{">>=", token.TokenAssign},
+	}
+	for _, test := range tests {
+		tok, err := firstToken(test.src + " x")
+		if tok.Type != test.typ || tok.Value != test.src || err != "" {
+			t.Errorf("%s: got %v %q %q, want %v", test.src, tok.Type, tok.Value, err, test.typ)
+		}
+	}
+}
+
+func TestIdentifier(t *testing.T) {
+	var tests = []struct {
+		src string
+		id  string
+		err string
+	}{
+		{"abc", "abc", ""},
+		{"_x1", "_x1", ""},
+		{"größe", "größe", ""},
+		{"変数 x", "変数", ""},
+		{"\ufeffbom", "bom", ""},
+		{"\xffx", "x", "invalid UTF-8 encoding"},
+		{"€x", "x", "unexpected character '€'"},
+	}
+	for _, test := range tests {
+		tok, err := firstToken(test.src)
+		if tok.Type != token.TokenID || tok.Value != test.id {
+			t.Errorf("%q: got %v %q, want identifier %q", test.src, tok.Type, tok.Value, test.id)
+		}
+		if err != test.err {
+			t.Errorf("%q: got error %q, want %q", test.src, err, test.err)
+		}
+	}
+}
+
+func TestColumn(t *testing.T) {
+	// columns count runes, not bytes
+	var l = NewLexer([]byte("var größe = \"日本\" + x\n"))
+	var want = []int{1, 5, 11, 13, 18, 20, 21}
+	for i, col := range want {
+		var tok = l.GetNextToken()
+		if got := l.file.Position(tok.Pos).Column; got != col {
+			t.Errorf("token %d %q: got column %d, want %d", i, tok.Value, got, col)
+		}
+	}
+}
+
+func TestComment(t *testing.T) {
+	var tests = []struct {
+		src  string
+		lead []string // the comments in front of every token but EOF
+		err  string
+	}{
+		{"x // end\ny", []string{"", "// end", ""}, ""},
+		{"// one\n// two\nx", []string{"", "", "// one|// two"}, ""},
+		{"/* a */ x /* b */\n", []string{"/* a */", "/* b */"}, ""},
+		{"/* a /* b */ c */ x", []string{"/* a /* b */ c */"}, ""},
+		{"/* a\n * b\n */\nx", []string{"", "/* a\n * b\n */"}, ""},
+		{"x // end\r\n", []string{"", "// end"}, ""},
+		{"/* a /* b */ x", nil, "comment not terminated"},
+	}
+	for _, test := range tests {
+		var l = NewLexer([]byte(test.src))
+		var lead []string
+		for tok := l.GetNextToken(); tok.Type != token.TokenEOF; tok = l.GetNextToken() {
+			var text []string
+			for _, c := range tok.Lead {
+				text = append(text, c.Text)
+			}
+			lead = append(lead, strings.Join(text, "|"))
+		}
+		if strings.Join(lead, ",") != strings.Join(test.lead, ",") {
+			t.Errorf("%q: got comments %q, want %q", test.src, lead, test.lead)
+		}
+		var err string
+		if len(l.diags) > 0 {
+			err = l.diags[0].Message
+		}
+		if err != test.err {
+			t.Errorf("%q: got error %q, want %q", test.src, err, test.err)
+		}
+	}
+}
+
+// tokens returns the tokens of l with their positions and the errors.
+func tokens(l *Lexer) string {
+	var list []string
+	for _, t := range l.LexerToken() {
+		list = append(list, fmt.Sprintf("%v %d", t, t.Pos))
+	}
+	for _, d := range l.Diagnostics() {
+		list = append(list, d.Message)
+	}
+	return strings.Join(list, "\n")
+}
+
+func TestReader(t *testing.T) {
+	var src = "var größe = 0..10 // a comment\n" +
+		"x <<= 0x1F + 2.5e3 /* a /* nested */ comment */\n" +
+		"var s = \"a\\tb\\u{1F600}\" + `raw\nstring`\n" +
+		"if a >= b && !c { s = \"日本\" } \xff\n"
+	// the bufio.Reader of the lexer holds 4096 bytes, the padding moves
+	// every token across the end of its buffer
+	for pad := 4096 - len(src); pad <= 4096; pad++ {
+		var text = strings.Repeat(" ", pad) + src
+		var want = tokens(NewLexer([]byte(text)))
+		for _, r := range []io.Reader{
+			strings.NewReader(text),
+			iotest.OneByteReader(strings.NewReader(text)),
+			iotest.HalfReader(strings.NewReader(text)),
+		} {
+			var file = token.NewFileSet().AddFile("", 0)
+			if got := tokens(NewReaderLexer(file, r)); got != want {
+				t.Fatalf("padding %d, %T: got\n%s\nwant\n%s", pad, r, got, want)
+			}
+			if file.Size() != len(text) {
+				t.Errorf("padding %d, %T: file size %d, want %d", pad, r, file.Size(), len(text))
+			}
+		}
+	}
+}
+
+func TestReaderError(t *testing.T) {
+	var r = io.MultiReader(strings.NewReader("var a = 1\nvar b"), iotest.ErrReader(errors.New("disk failed")))
+	var l = NewReaderLexer(token.NewFileSet().AddFile("", 0), r)
+	var got []string
+	for _, tok := range l.LexerToken() {
+		got = append(got, tok.Value)
+	}
+	if strings.Join(got, " ") != "VAR a = 1 ENTER VAR b EOF" {
+		t.Errorf("got tokens %q", got)
+	}
+	if d := l.Diagnostics(); len(d) != 1 || d[0].Message != "disk failed" {
+		t.Errorf("got errors %v, want disk failed", d)
+	}
+}
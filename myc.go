@@ -1,31 +1,132 @@
-package main
+// Package myc compiles and runs myc programs, it is the API for Go programs
+// that embed myc as a scripting language:
+//
+//	prog, diags := myc.Compile(src)
+//	if len(diags) > 0 {
+//		// report diags
+//	}
+//	err := prog.Run(ctx)
+//
+// The packages below it hold the parts of the compiler: token, lexer,
+// parser, ast, types, interp, codegen and format. The myc command is in
+// cmd/myc.
+package myc
 
 import (
-	"fmt"
-	"io/ioutil"
-	"log"
-	"os"
+	"bytes"
+	"context"
+	"io"
+
+	"myc/ast"
+	"myc/codegen"
+	"myc/diag"
+	"myc/interp"
+	"myc/lexer"
+	"myc/parser"
+	"myc/token"
+	"myc/types"
 )
 
-func main() {
-	b, err := ioutil.ReadFile("test01.myc")
-	if err != nil {
-		panic(err)
+// Diagnostic is an error found in a program, with its file, line and column.
+type Diagnostic = diag.Diagnostic
+
+// Module is a set of Go functions that programs can import, see
+// interp.Module for the types they can use.
+type Module = interp.Module
+
+// Register makes the functions of m available to programs that import
+// path, exp. a program that imports "app" calls m["log"] as app.log(...).
+func Register(path string, m Module) error {
+	return interp.Register(path, m)
+}
+
+// Program is a compiled myc program.
+type Program struct {
+	file    *token.File
+	ast     ast.AST
+	info    *types.TypeInfo
+	allowed []string
+	out     io.Writer
+}
+
+// Compile parses and type checks src. The diagnostics are sorted by
+// position, when any of them is an error the program is nil.
+func Compile(src []byte) (*Program, []Diagnostic) {
+	return CompileFile("", src)
+}
+
+// CompileFile is Compile for the content of a file, name is the file name
+// used in diagnostics.
+func CompileFile(name string, src []byte) (*Program, []Diagnostic) {
+	return compile(token.NewFileSet().AddFile(name, len(src)), bytes.NewReader(src))
+}
+
+// CompileReader is CompileFile for a file read from r. It is read as the
+// parser needs it, a read error is reported as a diagnostic.
+func CompileReader(name string, r io.Reader) (*Program, []Diagnostic) {
+	return compile(token.NewFileSet().AddFile(name, 0), r)
+}
+
+func compile(file *token.File, r io.Reader) (*Program, []Diagnostic) {
+	var l = lexer.NewReaderLexer(file, r)
+	var p = parser.NewLexerParse(l)
+	var node = p.Parse()
+	var diags = append(l.Diagnostics(), p.Diagnostics()...)
+	var info *types.TypeInfo
+	if diags.Err() == nil {
+		var tc = types.NewTypeCheckVisitor(node)
+		if list, ok := tc.Exec().(diag.Diagnostics); ok {
+			diags = append(diags, list...)
+		}
+		info = tc.Info()
 	}
-	l := NewLexer(b)
-	tokens := l.LexerToken()
-	fmt.Println("lexer success:", tokens)
-	// return
-	p := NewParse(tokens)
-	f, err := os.Create("tmp/test.go")
-	if err != nil {
-		log.Println(err)
-		return
+	diags.Resolve(file)
+	diags.Sort()
+	if diags.Err() != nil {
+		return nil, diags
 	}
-	defer f.Close()
+	return &Program{file: file, ast: node, info: info}, diags
+}
+
+// AST returns the syntax tree of the program.
+func (p *Program) AST() ast.AST {
+	return p.ast
+}
+
+// Allow lets the program import the restricted standard modules of names
+// when it is run, exp. os to read and write files.
+func (p *Program) Allow(names ...string) {
+	p.allowed = append(p.allowed, names...)
+}
+
+// SetOutput makes the program write its output to w when it is run, the
+// default is os.Stdout.
+func (p *Program) SetOutput(w io.Writer) {
+	p.out = w
+}
+
+// Run runs the program with the interpreter. It stops when ctx is done and
+// then returns ctx.Err(), a runtime error is returned as a Diagnostic.
+func (p *Program) Run(ctx context.Context) error {
+	var ev = interp.NewExecVisitor(p.ast)
+	ev.Allow(p.allowed...)
+	if p.out != nil {
+		ev.SetOutput(p.out)
+	}
+	var err = ev.ExecContext(ctx)
+	if d, ok := err.(diag.Diagnostic); ok {
+		d.Resolve(p.file)
+		return d
+	}
+	return err
+}
+
+// WriteC writes the program as C source to w.
+func (p *Program) WriteC(w io.Writer) error {
+	return codegen.NewExportCVisitor(p.ast, p.info, w).Exec()
+}
 
-	ev := NewExportGoVisitor(p.parse(), f)
-	// ev := NewExecVisitor(p.parse())
-	ev.Exec()
-	log.Printf("%v", ev.st)
+// WriteGo writes the program as Go source to w.
+func (p *Program) WriteGo(w io.Writer) error {
+	return codegen.NewExportGoVisitor(p.ast, p.info, w).Exec()
 }
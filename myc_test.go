@@ -0,0 +1,55 @@
+package myc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestCompileReader(t *testing.T) {
+	var src = "import \"stdio\"\nfor i in 0..3 {\n\tstdio.printf(\"%d\\n\", i * 2)\n}\n"
+	prog, diags := CompileReader("loop.myc", iotest.OneByteReader(strings.NewReader(src)))
+	if len(diags) > 0 {
+		t.Fatal(diags)
+	}
+	var out bytes.Buffer
+	prog.SetOutput(&out)
+	if err := prog.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "0\n2\n4\n" {
+		t.Errorf("got output %q", out.String())
+	}
+}
+
+func TestCompileReaderErrors(t *testing.T) {
+	var tests = []struct {
+		r    io.Reader
+		want []string
+	}{
+		{strings.NewReader("var a = 1\nvar b = a +\n"), []string{"bad.myc:2:12: expected expression, found newline"}},
+		{
+			// the source ends at the read error
+			io.MultiReader(strings.NewReader("var a = 1\nvar b"), iotest.ErrReader(errors.New("disk failed"))),
+			[]string{"bad.myc:2:6: disk failed", "bad.myc:2:6: expected assignment, found EOF"},
+		},
+	}
+	for _, test := range tests {
+		prog, diags := CompileReader("bad.myc", test.r)
+		if prog != nil {
+			t.Errorf("%q: got a program", test.want)
+		}
+		var got []string
+		for _, d := range diags {
+			got = append(got, fmt.Sprintf("%s:%d:%d: %s", d.File, d.Line, d.Column, d.Message))
+		}
+		if strings.Join(got, "\n") != strings.Join(test.want, "\n") {
+			t.Errorf("got %q, want %q", got, test.want)
+		}
+	}
+}
@@ -0,0 +1,882 @@
+// Package parser builds the syntax tree of myc programs from tokens.
+package parser
+
+import (
+	"strings"
+
+	"myc/ast"
+	"myc/diag"
+	"myc/lexer"
+	"myc/token"
+	"myc/trace"
+)
+
+// NewParse returns a parser for a list of tokens ending with EOF.
+func NewParse(tokens []*token.Token) *Parse {
+	return &Parse{next: func() *token.Token {
+		var t = tokens[0]
+		if len(tokens) > 1 {
+			tokens = tokens[1:]
+		}
+		return t
+	}}
+}
+
+// NewLexerParse returns a parser that reads the tokens from l as it needs
+// them, so l can read its input as a stream.
+func NewLexerParse(l *lexer.Lexer) *Parse {
+	return &Parse{next: l.GetNextToken}
+}
+
+type Parse struct {
+	next  func() *token.Token // returns EOF forever at the end
+	ahead []*token.Token      // tokens read but not eaten, ahead[0] is the current one
+	prev  *token.Token        // the last eaten token
+
+	comments []token.Comment // the comments of all tokens read so far
+
+	diags diag.Diagnostics
+	trace trace.Tracer
+}
+
+// SetTracer makes the parser report every token it eats to t.
+func (p *Parse) SetTracer(t trace.Tracer) {
+	p.trace = t
+}
+
+// Diagnostics returns the syntax errors found by the parser.
+func (p *Parse) Diagnostics() diag.Diagnostics {
+	return p.diags
+}
+
+// Comments returns the comments of the tokens read so far, in source order.
+func (p *Parse) Comments() []token.Comment {
+	return p.comments
+}
+
+// errorf returns a diagnostic at the current token, parse functions panic
+// with it to stop parsing.
+func (p *Parse) errorf(format string, args ...interface{}) diag.Diagnostic {
+	return diag.Errorf(p.at(), format, args...)
+}
+
+// read returns the next token of the source and keeps its comments.
+func (p *Parse) read() *token.Token {
+	var t = p.next()
+	p.comments = append(p.comments, t.Lead...)
+	return t
+}
+
+// tok returns the current token.
+func (p *Parse) tok() *token.Token {
+	if len(p.ahead) == 0 {
+		p.ahead = append(p.ahead, p.read())
+	}
+	return p.ahead[0]
+}
+
+// at returns the position of the current token.
+func (p *Parse) at() token.Pos {
+	return p.tok().Pos
+}
+
+// last returns the end of the last eaten token.
+func (p *Parse) last() token.Pos {
+	if p.prev == nil {
+		return p.at()
+	}
+	return p.prev.End
+}
+
+// spanFrom returns the span from pos to the end of the last eaten token.
+func (p *Parse) spanFrom(pos token.Pos) ast.Span {
+	return ast.Span{Start: pos, Stop: p.last()}
+}
+
+// peek returns the type of the k'th token after the current one.
+func (p *Parse) peek(k int) token.TokenType {
+	for len(p.ahead) <= k {
+		if n := len(p.ahead); n > 0 && p.ahead[n-1].Type == token.TokenEOF {
+			return token.TokenEOF
+		}
+		p.ahead = append(p.ahead, p.read())
+	}
+	return p.ahead[k].Type
+}
+
+// advance moves to the next token, it stays at EOF.
+func (p *Parse) advance() {
+	var t = p.tok()
+	if t.Type == token.TokenEOF {
+		return
+	}
+	p.prev = t
+	p.ahead = p.ahead[1:]
+}
+
+func (p *Parse) mustEat(t token.TokenType) string {
+	if p.tok().Type == t {
+		if p.trace != nil {
+			p.trace.Trace(trace.Event{Kind: trace.Parse, Token: p.tok()})
+		}
+		if t == token.TokenEOF {
+			return "EOF"
+		}
+		p.advance()
+		return p.prev.Value
+	}
+	panic(p.errorf("expected %v, found %s", t, p.tok().Describe()))
+}
+
+// func (p *Parse) eat(t TokenType) (bool, string) {
+// 	if p.tok().Type == t {
+// 		t := p.tok()
+// 		if p.peekToken != nil {
+// 			p.tok(), p.peekToken = p.peekToken, nil
+// 		} else {
+// 			p.tok() = p.l.GetNextToken()
+// 		}
+// 		return true, t.Value
+// 	}
+// 	return false, ""
+// }
+
+// Parse returns the program. Syntax errors are recorded in Diagnostics and the
+// statements that have them are replaced by ASTError.
+func (p *Parse) Parse() ast.AST {
+	return p.program()
+}
+
+// try runs fn, when it fails with a syntax error the error is recorded and
+// the tokens up to the next statement are skipped.
+func (p *Parse) try(fn func() ast.AST) (node ast.AST) {
+	var pos = p.at()
+	defer func() {
+		if r := recover(); r != nil {
+			d, ok := r.(diag.Diagnostic)
+			if !ok {
+				panic(r)
+			}
+			p.report(d)
+			p.sync()
+			node = ast.ASTError{Span: p.spanFrom(pos)}
+		}
+	}()
+	return fn()
+}
+
+// report records d unless there already is an error at the same place.
+func (p *Parse) report(d diag.Diagnostic) {
+	if n := len(p.diags); n > 0 && p.diags[n-1].Pos == d.Pos {
+		return
+	}
+	p.diags = append(p.diags, d)
+}
+
+// sync skips tokens until the end of the statement or the start of the next one.
+// Blocks opened in the skipped tokens are skipped with their closing brace,
+// only a brace that closes the block around the statement stops it.
+func (p *Parse) sync() {
+	var depth int
+	for {
+		switch t := p.tok().Type; {
+		case t == token.TokenEOF:
+			return
+		case t == token.TokenLBrace:
+			depth++
+		case t == token.TokenRBrace:
+			if depth == 0 {
+				return
+			}
+			depth--
+		case depth == 0 && (p.stmtEnd() || p.stmtStart()):
+			return
+		}
+		p.advance()
+	}
+}
+
+func (p *Parse) stmtEnd() bool {
+	switch p.tok().Type {
+	case token.TokenEOF, token.TokenEnter, token.TokenRBrace:
+		return true
+	}
+	return false
+}
+
+func (p *Parse) stmtStart() bool {
+	switch p.tok().Type {
+	case token.TokenIf, token.TokenFunction, token.TokenVar, token.TokenWhile, token.TokenFor:
+		return true
+	}
+	return false
+}
+
+// program : Enter* import stmt_list EOF
+func (p *Parse) program() ast.AST {
+	for p.tok().Type == token.TokenEnter {
+		p.mustEat(token.TokenEnter)
+	}
+	var pos = p.at()
+	var imports = p._import()
+	var list = p.stmtList()
+	for p.tok().Type != token.TokenEOF {
+		// a stray closing brace, skip it and go on
+		p.report(p.errorf("unexpected %s", p.tok().Describe()))
+		p.advance()
+		var more = p.stmtList()
+		list.List = append(list.List, more.List...)
+		list.Stop = more.Stop
+	}
+	return ast.ASTProject{Span: p.spanFrom(pos), Imports: imports, StmtList: list}
+}
+
+// import : (Import String Enter)*
+func (p *Parse) _import() []ast.ASTImport {
+	var list []ast.ASTImport
+	for p.tok().Type == token.TokenImport {
+		p.try(func() ast.AST {
+			var pos = p.at()
+			p.mustEat(token.TokenImport)
+			var path = p.mustEat(token.TokenString)
+			list = append(list, ast.ASTImport{Span: p.spanFrom(pos), Path: path})
+			return nil
+		})
+		for p.tok().Type == token.TokenEnter {
+			p.mustEat(token.TokenEnter)
+		}
+	}
+	return list
+}
+
+// stmt_list : stmt | stmt Enter stmt_list
+func (p *Parse) stmtList() ast.ASTStmt {
+	var pos = p.at()
+	var list []ast.AST
+	for {
+		var node = p.try(func() ast.AST {
+			var node = p.stmt()
+			if !p.stmtEnd() {
+				panic(p.errorf("unexpected %s at end of statement", p.tok().Describe()))
+			}
+			return node
+		})
+		if node != nil {
+			list = append(list, node)
+		}
+		if p.tok().Type == token.TokenEnter {
+			p.mustEat(token.TokenEnter)
+			continue
+		}
+		// go on after an error that stopped in front of a statement
+		if _, ok := node.(ast.ASTError); ok && p.stmtStart() {
+			continue
+		}
+		return ast.ASTStmt{Span: p.spanFrom(pos), List: list}
+	}
+}
+
+// stmt : LBrace stmt_list RBrace
+//
+//	| IF expr LBrace stmt_list RBrace _else
+//	| IF expr THEN stmt _else
+//	| While expr block
+//	| For (LBrace | for_clause | range_clause) ...
+//	| Break
+//	| Continue
+//	| function(Function...)
+//	| Return expr (Comma Enter? expr)* (Colon Number)?
+//	| Var variable type? (Comma variable type?)* ASSIGN expr (Comma expr)*
+//	| variable (Comma variable)* ASSIGN expr (Comma expr)*
+//	| expr
+//	| empty
+func (p *Parse) stmt() ast.AST {
+	var pos = p.at()
+	if p.tok().Type == token.TokenLBrace {
+		return p.block()
+	}
+
+	if p.tok().Type == token.TokenIf {
+		p.mustEat(token.TokenIf)
+		logic := p.expr()
+		var node = ast.ASTBranch{Cond: logic}
+		if p.tok().Type == token.TokenLBrace {
+			node.Then = p.block()
+		} else {
+			p.mustEat(token.TokenThen)
+			node.Then = p.stmt()
+		}
+		node.Else = p._else()
+		node.Span = p.spanFrom(pos)
+		return node
+	}
+
+	if p.tok().Type == token.TokenWhile {
+		p.mustEat(token.TokenWhile)
+		var logic = p.expr()
+		var stmt = p.block()
+		return ast.ASTWhile{Span: p.spanFrom(pos), Cond: logic, Body: stmt}
+	}
+
+	if p.tok().Type == token.TokenFor {
+		return p._for()
+	}
+
+	if p.tok().Type == token.TokenBreak {
+		p.mustEat(token.TokenBreak)
+		return ast.ASTBreak{Span: p.spanFrom(pos)}
+	}
+
+	if p.tok().Type == token.TokenContinue {
+		p.mustEat(token.TokenContinue)
+		return ast.ASTContinue{Span: p.spanFrom(pos)}
+	}
+
+	if p.tok().Type == token.TokenFunction {
+		return p.function()
+	}
+
+	if p.tok().Type == token.TokenReturn {
+		p.mustEat(token.TokenReturn)
+		var exprs []ast.AST
+		if p.stmtEnd() {
+			return ast.ASTReturn{Span: p.spanFrom(pos)}
+		}
+		exprs = append(exprs, p.expr())
+		for p.tok().Type == token.TokenComma {
+			p.mustEat(token.TokenComma)
+			for p.tok().Type == token.TokenEnter {
+				p.mustEat(token.TokenEnter)
+			}
+			exprs = append(exprs, p.expr())
+		}
+		if p.tok().Type != token.TokenColon {
+			return ast.ASTReturn{Span: p.spanFrom(pos), Exprs: exprs}
+		}
+		p.mustEat(token.TokenColon)
+		var err = p.mustEat(token.TokenNumber)
+		return ast.ASTReturn{Span: p.spanFrom(pos), Exprs: exprs, Error: err}
+	}
+
+	if p.tok().Type == token.TokenID && (p.peek(1) != token.TokenComma && p.peek(1) != token.TokenAssign) || p.exprStart() { // expr
+		return p.expr()
+	}
+
+	if p.tok().Type == token.TokenVar || p.tok().Type == token.TokenID {
+		var isDefined bool
+		if p.tok().Type == token.TokenVar {
+			p.mustEat(token.TokenVar)
+			isDefined = true
+		}
+		var left []ast.ASTVariable
+		left = append(left, p.declVariable(isDefined))
+		for p.tok().Type == token.TokenComma {
+			p.mustEat(token.TokenComma)
+			left = append(left, p.declVariable(isDefined))
+		}
+		var op = p.mustEat(token.TokenAssign)
+		var right []ast.AST
+		right = append(right, p.expr())
+		for p.tok().Type == token.TokenComma {
+			p.mustEat(token.TokenComma)
+			right = append(right, p.expr())
+		}
+		return ast.ASTAssign{
+			Span:      p.spanFrom(pos),
+			Left:      left,
+			Op:        op,
+			Right:     right,
+			IsDefined: isDefined,
+		}
+	}
+
+	return ast.ASTEmpty{Span: ast.Span{Start: pos, Stop: pos}}
+}
+
+// exprStart reports whether the token starts an expression other than a
+// variable, those are told apart from assignments by stmt.
+func (p *Parse) exprStart() bool {
+	switch p.tok().Type {
+	case token.TokenNumber, token.TokenString, token.TokenTrue, token.TokenFalse, token.TokenLParen,
+		token.TokenMinus, token.TokenNot, token.TokenNotSlower, token.TokenUnaryOp:
+		return true
+	}
+	return false
+}
+
+// _for : For block
+//
+//	| For variable In expr Range expr block
+//	| For stmt? Semicolon expr? Semicolon stmt? block
+func (p *Parse) _for() ast.AST {
+	var pos = p.at()
+	p.mustEat(token.TokenFor)
+	if p.tok().Type == token.TokenLBrace {
+		var stmt = p.block()
+		return ast.ASTFor{Span: p.spanFrom(pos), Body: stmt}
+	}
+	if p.tok().Type == token.TokenID && p.peek(1) == token.TokenIn {
+		var key = p.variable()
+		p.mustEat(token.TokenIn)
+		var from = p.expr()
+		p.mustEat(token.TokenRange)
+		var to = p.expr()
+		var stmt = p.block()
+		return ast.ASTRange{Span: p.spanFrom(pos), Key: key, From: from, To: to, Body: stmt}
+	}
+
+	var node ast.ASTFor
+	if p.tok().Type != token.TokenSemicolon {
+		node.Init = p.stmt()
+	}
+	p.mustEat(token.TokenSemicolon)
+	if p.tok().Type != token.TokenSemicolon {
+		node.Cond = p.expr()
+	}
+	p.mustEat(token.TokenSemicolon)
+	if p.tok().Type != token.TokenLBrace {
+		node.Post = p.stmt()
+	}
+	node.Body = p.block()
+	node.Span = p.spanFrom(pos)
+	return node
+}
+
+// block : LBrace stmt_list RBrace
+func (p *Parse) block() ast.ASTStmt {
+	var pos = p.at()
+	p.mustEat(token.TokenLBrace)
+	var node = p.stmtList()
+	p.mustEat(token.TokenRBrace)
+	node.Span = p.spanFrom(pos)
+	return node
+}
+
+// function : Function variable def_params results? LBrace stmt_list RBrace
+func (p *Parse) function() ast.ASTFunction {
+	var pos = p.at()
+	var doc = docComments(p.tok())
+	p.mustEat(token.TokenFunction)
+	name := p.variable()
+	params := p.defParams()
+	var _return []ast.ASTVariable
+	if p.tok().Type == token.TokenLParen || p.typeStart() {
+		_return = p.results()
+	}
+	return ast.ASTFunction{
+		DocComments: doc,
+		Name:        name,
+		Params:      params,
+		Body:        p.block(),
+		Results:     _return,
+		Span:        p.spanFrom(pos),
+	}
+}
+
+// docComments returns the comments in front of t that end directly above
+// it, without a blank line in between.
+func docComments(t *token.Token) []token.Comment {
+	var line = t.Line
+	var i = len(t.Lead)
+	for i > 0 && t.Lead[i-1].Line+t.Lead[i-1].Lines() == line {
+		i--
+		line = t.Lead[i].Line
+	}
+	return t.Lead[i:]
+}
+
+// def_params : LParen (ID type? (Comma Enter*)?)* RParen
+func (p *Parse) defParams() []ast.ASTVariable {
+	p.mustEat(token.TokenLParen)
+	var list []ast.ASTVariable
+	for p.tok().Type == token.TokenID {
+		list = append(list, p.declVariable(true))
+		if p.tok().Type == token.TokenComma {
+			p.mustEat(token.TokenComma)
+			for p.tok().Type == token.TokenEnter {
+				p.mustEat(token.TokenEnter)
+			}
+		}
+	}
+	p.mustEat(token.TokenRParen)
+	return list
+}
+
+// results : LParen (type (Comma Enter*)?)* RParen
+//
+//	| type
+//
+// the results are variables without a name, exp. func div(a int, b int)(int, int)
+func (p *Parse) results() []ast.ASTVariable {
+	var list []ast.ASTVariable
+	if p.tok().Type != token.TokenLParen {
+		var pos = p.at()
+		var ty = p._type()
+		return append(list, ast.ASTVariable{Span: p.spanFrom(pos), Type: ty})
+	}
+	p.mustEat(token.TokenLParen)
+	for p.tok().Type != token.TokenRParen {
+		var pos = p.at()
+		var ty = p._type()
+		list = append(list, ast.ASTVariable{Span: p.spanFrom(pos), Type: ty})
+		if p.tok().Type != token.TokenComma {
+			break
+		}
+		p.mustEat(token.TokenComma)
+		for p.tok().Type == token.TokenEnter {
+			p.mustEat(token.TokenEnter)
+		}
+	}
+	p.mustEat(token.TokenRParen)
+	return list
+}
+
+// _type : ID (Dot ID)*
+//
+//	| Mul _type
+//	| LBracket Number? RBracket _type
+//	| ID(map) LBracket _type RBracket _type
+//	| Function LParen (_type (Comma _type)*)? RParen results?
+//
+// the type is returned in its canonical spelling, exp. []int or func(int) (int, string)
+func (p *Parse) _type() string {
+	switch p.tok().Type {
+	case token.TokenMul:
+		p.mustEat(token.TokenMul)
+		return "*" + p._type()
+	case token.TokenLBracket:
+		p.mustEat(token.TokenLBracket)
+		var n string
+		if p.tok().Type == token.TokenNumber {
+			n = p.mustEat(token.TokenNumber)
+		}
+		p.mustEat(token.TokenRBracket)
+		return "[" + n + "]" + p._type()
+	case token.TokenFunction:
+		p.mustEat(token.TokenFunction)
+		p.mustEat(token.TokenLParen)
+		var params []string
+		for p.tok().Type != token.TokenRParen {
+			params = append(params, p._type())
+			if p.tok().Type != token.TokenComma {
+				break
+			}
+			p.mustEat(token.TokenComma)
+		}
+		p.mustEat(token.TokenRParen)
+		var ty = "func(" + strings.Join(params, ", ") + ")"
+		if p.tok().Type == token.TokenLParen || p.typeStart() {
+			var results []string
+			for _, r := range p.results() {
+				results = append(results, r.Type)
+			}
+			if len(results) == 1 {
+				return ty + " " + results[0]
+			}
+			return ty + " (" + strings.Join(results, ", ") + ")"
+		}
+		return ty
+	case token.TokenID:
+		var name = p.variable().Name
+		if name == "map" && p.tok().Type == token.TokenLBracket {
+			p.mustEat(token.TokenLBracket)
+			var key = p._type()
+			p.mustEat(token.TokenRBracket)
+			return "map[" + key + "]" + p._type()
+		}
+		return name
+	}
+	panic(p.errorf("expected type, found %s", p.tok().Describe()))
+}
+
+// params : LParen (expr (Comma Enter* expr)* (Comma Enter*)?)? RParen
+func (p *Parse) params() []ast.AST {
+	p.mustEat(token.TokenLParen)
+	var list []ast.AST
+	for p.tok().Type != token.TokenRParen {
+		list = append(list, p.expr())
+		if p.tok().Type == token.TokenRParen {
+			break
+		}
+		p.mustEat(token.TokenComma)
+		for p.tok().Type == token.TokenEnter {
+			p.mustEat(token.TokenEnter)
+		}
+	}
+	p.mustEat(token.TokenRParen)
+	return list
+}
+
+// _else : ELSE stmt
+//
+//	| empty
+func (p *Parse) _else() ast.AST {
+	if p.tok().Type != token.TokenElse {
+		return nil
+	}
+	p.mustEat(token.TokenElse)
+	return p.stmt()
+}
+
+// variable : ID (Dot ID)*
+func (p *Parse) variable() ast.ASTVariable {
+	var pos = p.at()
+	var name = p.mustEat(token.TokenID)
+	for p.tok().Type == token.TokenDot {
+		name += p.mustEat(token.TokenDot)
+		name += p.mustEat(token.TokenID)
+	}
+	return ast.ASTVariable{Span: p.spanFrom(pos), Name: name}
+}
+
+// declVariable : variable type?
+//
+// only declarations have a type, exp. var x int = 1
+func (p *Parse) declVariable(typed bool) ast.ASTVariable {
+	var pos = p.at()
+	var v = p.variable()
+	if !typed {
+		return v
+	}
+	if p.typeStart() {
+		v.Type = p._type()
+		v.Span = p.spanFrom(pos)
+	}
+	return v
+}
+
+// typeStart reports whether the current token can start a type.
+func (p *Parse) typeStart() bool {
+	switch p.tok().Type {
+	case token.TokenID, token.TokenMul, token.TokenLBracket, token.TokenFunction:
+		return true
+	}
+	return false
+}
+
+// op_0 : [] () . ->
+// op_1 : - * & ! ~ sizeof
+// op_2 : as
+// op_3 : / * %
+// op_4 : + -
+// op_5 : << >> & ^ |
+// op_6 : > >= < <= == !=
+// op_7 : &&
+// op_8 : ||
+// op_9 : = /= *= %= += -= <<= >>= &= ^= |=
+//
+// The keywords not, and, or are spellings of ! && || that bind looser than
+// all operators, so `not a == b or c` is `!(a == b) || c`.
+
+// expr : and_slower (OrSlower and_slower)*
+func (p *Parse) expr() ast.AST {
+	var left = p.andSlower()
+	for p.tok().Type == token.TokenOrSlower {
+		p.mustEat(token.TokenOrSlower)
+		var right = p.andSlower()
+		left = ast.ASTBinaryOp{
+			Span:  ast.Span{Start: left.Pos(), Stop: right.End()},
+			Left:  left,
+			Op:    "||",
+			Right: right,
+		}
+	}
+	return left
+}
+
+// and_slower : not_slower (AndSlower not_slower)*
+func (p *Parse) andSlower() ast.AST {
+	var left = p.notSlower()
+	for p.tok().Type == token.TokenAndSlower {
+		p.mustEat(token.TokenAndSlower)
+		var right = p.notSlower()
+		left = ast.ASTBinaryOp{
+			Span:  ast.Span{Start: left.Pos(), Stop: right.End()},
+			Left:  left,
+			Op:    "&&",
+			Right: right,
+		}
+	}
+	return left
+}
+
+// not_slower : NotSlower not_slower | op_8
+func (p *Parse) notSlower() ast.AST {
+	if p.tok().Type != token.TokenNotSlower {
+		return p.op8()
+	}
+	var pos = p.at()
+	p.mustEat(token.TokenNotSlower)
+	var node = p.notSlower()
+	return ast.ASTUnaryOp{
+		Span:    p.spanFrom(pos),
+		Op:      "!",
+		Operand: node,
+	}
+}
+
+// op_8 : op_7 (Or op_7)*
+func (p *Parse) op8() ast.AST {
+	var left = p.op7()
+	for p.tok().Type == token.TokenOr {
+		var op = p.mustEat(token.TokenOr)
+		var right = p.op7()
+		left = ast.ASTBinaryOp{
+			Span:  ast.Span{Start: left.Pos(), Stop: right.End()},
+			Left:  left,
+			Op:    op,
+			Right: right,
+		}
+	}
+	return left
+}
+
+// op_7 : op_6 (And op_6)*
+func (p *Parse) op7() ast.AST {
+	var left = p.op6()
+	for p.tok().Type == token.TokenAnd {
+		var op = p.mustEat(token.TokenAnd)
+		var right = p.op6()
+		left = ast.ASTBinaryOp{
+			Span:  ast.Span{Start: left.Pos(), Stop: right.End()},
+			Left:  left,
+			Op:    op,
+			Right: right,
+		}
+	}
+	return left
+}
+
+// op_6 : op_5 (Compare op_5)*
+func (p *Parse) op6() ast.AST {
+	var left = p.op5()
+	for p.tok().Type == token.TokenCompare {
+		var op = p.mustEat(token.TokenCompare)
+		var right = p.op5()
+		left = ast.ASTBinaryOp{
+			Span:  ast.Span{Start: left.Pos(), Stop: right.End()},
+			Left:  left,
+			Op:    op,
+			Right: right,
+		}
+	}
+	return left
+}
+
+// op_5 : op_4 ((BitOp | OpAnd) op_4)*
+func (p *Parse) op5() ast.AST {
+	var left = p.op4()
+	for p.tok().Type == token.TokenOpBit || p.tok().Type == token.TokenOpAnd {
+		var op = p.mustEat(p.tok().Type)
+		var right = p.op4()
+		left = ast.ASTBinaryOp{
+			Span:  ast.Span{Start: left.Pos(), Stop: right.End()},
+			Left:  left,
+			Op:    op,
+			Right: right,
+		}
+	}
+	return left
+}
+
+// op_4 : op_3 ((Plus | Minus) op_3)*
+func (p *Parse) op4() ast.AST {
+	var left = p.op3()
+	for p.tok().Type == token.TokenPlus || p.tok().Type == token.TokenMinus {
+		var op = p.mustEat(p.tok().Type)
+		var right = p.op3()
+		left = ast.ASTBinaryOp{
+			Span:  ast.Span{Start: left.Pos(), Stop: right.End()},
+			Left:  left,
+			Op:    op,
+			Right: right,
+		}
+	}
+	return left
+}
+
+// op_3 : op_2 ((Mul | Div | Mod) op_2)*
+func (p *Parse) op3() ast.AST {
+	var left = p.op2()
+	for t := p.tok().Type; t == token.TokenMul || t == token.TokenDiv || t == token.TokenMod; t = p.tok().Type {
+		var op = p.mustEat(p.tok().Type)
+		var right = p.op2()
+		left = ast.ASTBinaryOp{
+			Span:  ast.Span{Start: left.Pos(), Stop: right.End()},
+			Left:  left,
+			Op:    op,
+			Right: right,
+		}
+	}
+	return left
+}
+
+// op_2 : op_1 (As op_1)*
+func (p *Parse) op2() ast.AST {
+	var left = p.op1()
+	for p.tok().Type == token.TokenAs {
+		p.mustEat(token.TokenAs) // lexed upper case
+		var right = p.op1()
+		left = ast.ASTBinaryOp{
+			Span:  ast.Span{Start: left.Pos(), Stop: right.End()},
+			Left:  left,
+			Op:    "as",
+			Right: right,
+		}
+	}
+	return left
+}
+
+// op_1 : (Mul | Minus | OpAnd | Not | UnaryOp) op_1 | factor
+func (p *Parse) op1() ast.AST {
+	var pos = p.at()
+	var t = p.tok().Type
+	if t == token.TokenMul || t == token.TokenMinus || t == token.TokenOpAnd || t == token.TokenNot || t == token.TokenUnaryOp {
+		var op = p.mustEat(t)
+		var node = p.op1()
+		return ast.ASTUnaryOp{
+			Span:    p.spanFrom(pos),
+			Op:      op,
+			Operand: node,
+		}
+	}
+	return p.factor()
+}
+
+// factor : Number | String | True | False | LParen expr RParen | variable params?
+func (p *Parse) factor() ast.AST {
+	var pos = p.at()
+	switch p.tok().Type {
+	case token.TokenNumber:
+		var num = p.mustEat(token.TokenNumber)
+		n, err := ast.NewNumber(p.spanFrom(pos), num)
+		if err != nil {
+			p.report(diag.Errorf(pos, "%v", err))
+		}
+		return n
+	case token.TokenString:
+		var s = p.mustEat(token.TokenString)
+		return ast.ASTString{Span: p.spanFrom(pos), Value: s, Lit: p.prev.Lit}
+	case token.TokenTrue, token.TokenFalse:
+		var t = p.tok().Type
+		p.mustEat(t)
+		return ast.ASTBool{Span: p.spanFrom(pos), Value: t == token.TokenTrue}
+	case token.TokenLParen:
+		p.mustEat(token.TokenLParen)
+		var e = p.expr()
+		p.mustEat(token.TokenRParen)
+		return e
+	case token.TokenID:
+		var tmp = p.variable()
+		if p.tok().Type == token.TokenLParen {
+			var params = p.params()
+			return ast.ASTCallFunc{
+				Span: p.spanFrom(pos),
+				Name: tmp,
+				Args: params,
+			}
+		}
+		return tmp
+	}
+	panic(p.errorf("expected expression, found %s", p.tok().Describe()))
+}
@@ -0,0 +1,129 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"myc/ast"
+	"myc/lexer"
+	"myc/token"
+)
+
+func TestDoc(t *testing.T) {
+	var tests = []struct {
+		src string
+		doc string
+	}{
+		{"func f() {}\n", ""},
+		{"// f does it.\nfunc f() {}\n", "f does it."},
+		{"// f does\n//   it.\nfunc f() {}\n", "f does\n  it."},
+		{"/* f does\n   it. */\nfunc f() {}\n", "f does\n   it."},
+		{"// not doc\n\n// doc\nfunc f() {}\n", "doc"},
+		{"// not doc\n\nfunc f() {}\n", ""},
+		{"var a = 1 // not doc\nfunc f() {}\n", ""},
+	}
+	for _, test := range tests {
+		var l = lexer.NewLexer([]byte(test.src))
+		var p = NewLexerParse(l)
+		var project = p.Parse().(ast.ASTProject)
+		if len(l.Diagnostics()) > 0 || len(p.diags) > 0 {
+			t.Errorf("%q: %v %v", test.src, l.Diagnostics(), p.diags)
+			continue
+		}
+		var f ast.ASTFunction
+		for _, stmt := range project.StmtList.(ast.ASTStmt).List {
+			if fn, ok := stmt.(ast.ASTFunction); ok {
+				f = fn
+			}
+		}
+		if got := f.Doc(); got != test.doc {
+			t.Errorf("%q: got doc %q, want %q", test.src, got, test.doc)
+		}
+	}
+}
+
+// errors parses src and returns its syntax errors as line:column: message.
+func errors(src string) []string {
+	var file = token.NewFileSet().AddFile("", len(src))
+	_, list := parse(file, lexer.NewFileLexer(file, []byte(src)))
+	return list
+}
+
+// parse parses the tokens of l and returns the syntax tree and the errors
+// as line:column: message.
+func parse(file *token.File, l *lexer.Lexer) (ast.AST, []string) {
+	var p = NewLexerParse(l)
+	var node = p.Parse()
+	var diags = append(l.Diagnostics(), p.Diagnostics()...)
+	diags.Resolve(file)
+	var list []string
+	for _, d := range diags {
+		list = append(list, fmt.Sprintf("%d:%d: %s", d.Line, d.Column, d.Message))
+	}
+	return node, list
+}
+
+func TestErrors(t *testing.T) {
+	var tests = []struct {
+		src  string
+		errs []string
+	}{
+		{"var a = 1\n", nil},
+		{
+			"var a = 1 +\nvar b = )\nvar c = 3\n",
+			[]string{"1:12: expected expression, found newline", "2:9: expected expression, found ')'"},
+		},
+		{
+			// the block of a broken statement is skipped with its braces
+			"func f(a, 1) {\n\tvar x = 1\n\tif x {\n\t\tx = 2\n\t}\n}\nvar y = )\nfunc g() {\n}\n",
+			[]string{"1:11: expected ')', found number 1", "7:9: expected expression, found ')'"},
+		},
+		{
+			"if 1 < {\n\tvar a = 1\n}\nwhile true {\n\tvar = 2\n\tbreak\n}\nvar ok = 1\n",
+			[]string{"1:8: expected expression, found '{'", "5:6: expected identifier, found '='"},
+		},
+		{
+			"func f() {\n\tif 1 < {\n\t\tvar a = 1\n\t}\n\tvar b = )\n}\nvar c = ]\n",
+			[]string{"2:9: expected expression, found '{'", "5:10: expected expression, found ')'", "7:9: expected expression, found ']'"},
+		},
+		{
+			"if 1 < { var a = 1 }\nvar b = 2 +\n",
+			[]string{"1:8: expected expression, found '{'", "2:12: expected expression, found newline"},
+		},
+		{
+			"func f() {\n\tvar a = (1 + 2\n\tvar b = 3\n}\nvar c = f(1 2)\n}\nvar d = 4\n",
+			[]string{"2:16: expected ')', found newline", "5:13: expected ',', found number 2", "6:1: unexpected '}'"},
+		},
+	}
+	for _, test := range tests {
+		if got := errors(test.src); strings.Join(got, "\n") != strings.Join(test.errs, "\n") {
+			t.Errorf("%q:\ngot\n\t%s\nwant\n\t%s", test.src, strings.Join(got, "\n\t"), strings.Join(test.errs, "\n\t"))
+		}
+	}
+}
+
+func TestReader(t *testing.T) {
+	var tests = []string{
+		"import \"stdio\"\nfunc fib(n int) int {\n\tif n <= 1 {\n\t\treturn n\n\t}\n\treturn fib(n-1) + fib(n-2)\n}\n" +
+			"for i in 0..10 {\n\tstdio.printf(\"%d\\n\", fib(i))\n}\n",
+		"var größe = 1 << 2 // comment\nvar s = `raw\nstring` + \"日本\"\ngröße <<= 3\n",
+		"func f() {\n\tif 1 < {\n\t\tvar a = 1\n\t}\n\tvar b = )\n}\nvar c = ]\n",
+		"func f() {\n\tvar a = (1 + 2\n\tvar b = 3\n}\nvar c = f(1 2)\n}\nvar d = 4\n",
+	}
+	for _, src := range tests {
+		var file = token.NewFileSet().AddFile("", len(src))
+		want, wantErrs := parse(file, lexer.NewFileLexer(file, []byte(src)))
+		// the lexer reads one byte at a time, the lookahead of the lexer
+		// and of the parser crosses every read
+		file = token.NewFileSet().AddFile("", 0)
+		got, gotErrs := parse(file, lexer.NewReaderLexer(file, iotest.OneByteReader(strings.NewReader(src))))
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("%q: got\n%v\nwant\n%v", src, got, want)
+		}
+		if strings.Join(gotErrs, "\n") != strings.Join(wantErrs, "\n") {
+			t.Errorf("%q: got errors\n\t%s\nwant\n\t%s", src, strings.Join(gotErrs, "\n\t"), strings.Join(wantErrs, "\n\t"))
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package stdlib
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Format is a printf format of C, the first argument of stdio.printf and
+// stdio.sprintf. The conversions are those of C for ints (d i u o x X c),
+// floats (f F e E g G) and strings (s) with their flags, width and
+// precision. Length modifiers like the l of %ld are dropped, myc has a
+// single size of ints and floats. The C format reads ints with the ll
+// modifier, myc ints are 64 bits, only %c and * read an int.
+type Format struct {
+	Go   string      // the format for Go's fmt package
+	C    string      // the format for C
+	Args []FormatArg // the arguments it reads
+}
+
+// FormatArg is an argument read by a format.
+type FormatArg struct {
+	Conv string // the conversion that reads it, exp. %5.2f
+	Type string // int, float or string
+	Star bool   // it is the * width or precision of Conv
+}
+
+// formatVerbs are the C conversions by the type they read and their Go
+// verb.
+var formatVerbs = map[byte]struct {
+	typ  string
+	verb byte
+}{
+	'd': {"int", 'd'}, 'i': {"int", 'd'}, 'u': {"int", 'd'},
+	'o': {"int", 'o'}, 'x': {"int", 'x'}, 'X': {"int", 'X'}, 'c': {"int", 'c'},
+	'f': {"float", 'f'}, 'F': {"float", 'F'}, 'e': {"float", 'e'},
+	'E': {"float", 'E'}, 'g': {"float", 'g'}, 'G': {"float", 'G'},
+	's': {"string", 's'},
+}
+
+// ParseFormat parses a printf format of C.
+func ParseFormat(s string) (*Format, error) {
+	var f Format
+	var g, c strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			g.WriteByte(s[i])
+			c.WriteByte(s[i])
+			continue
+		}
+		var start = i
+		i++
+		if i < len(s) && s[i] == '%' {
+			g.WriteString("%%")
+			c.WriteString("%%")
+			continue
+		}
+		for i < len(s) && strings.IndexByte("-+ #0", s[i]) >= 0 {
+			i++
+		}
+		// the width and precision can be read from int arguments
+		var stars []FormatArg
+		for _, prefix := range []string{"", "."} {
+			if !strings.HasPrefix(s[i:], prefix) {
+				continue
+			}
+			i += len(prefix)
+			if i < len(s) && s[i] == '*' {
+				stars = append(stars, FormatArg{Type: "int", Star: true})
+				i++
+				continue
+			}
+			for i < len(s) && '0' <= s[i] && s[i] <= '9' {
+				i++
+			}
+		}
+		var spec = s[start:i]
+		for i < len(s) && strings.IndexByte("hlLqjzt", s[i]) >= 0 {
+			i++
+		}
+		if i == len(s) {
+			return nil, fmt.Errorf("missing conversion at the end of %q", s)
+		}
+		v, ok := formatVerbs[s[i]]
+		if !ok {
+			return nil, fmt.Errorf("unknown conversion %s%c", s[start:i], s[i])
+		}
+		for _, a := range stars {
+			a.Conv = spec + string(s[i])
+			f.Args = append(f.Args, a)
+		}
+		f.Args = append(f.Args, FormatArg{Conv: spec + string(s[i]), Type: v.typ})
+		g.WriteString(spec + string(v.verb))
+		if v.typ == "int" && s[i] != 'c' {
+			c.WriteString(spec + "ll" + string(s[i]))
+		} else {
+			c.WriteString(spec + string(s[i]))
+		}
+	}
+	f.Go, f.C = g.String(), c.String()
+	return &f, nil
+}
@@ -0,0 +1,37 @@
+package stdlib
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFormat(t *testing.T) {
+	var tests = []struct {
+		format string
+		goFmt  string
+		c      string
+		args   []FormatArg
+		err    string
+	}{
+		{"100%%\n", "100%%\n", "100%%\n", nil, ""},
+		{"%d %i %u", "%d %d %d", "%lld %lli %llu", []FormatArg{{"%d", "int", false}, {"%i", "int", false}, {"%u", "int", false}}, ""},
+		{"%ld %05x", "%d %05x", "%lld %05llx", []FormatArg{{"%d", "int", false}, {"%05x", "int", false}}, ""},
+		{"%c", "%c", "%c", []FormatArg{{"%c", "int", false}}, ""},
+		{"%-4s|%5.2f", "%-4s|%5.2f", "%-4s|%5.2f", []FormatArg{{"%-4s", "string", false}, {"%5.2f", "float", false}}, ""},
+		{"%*.*d", "%*.*d", "%*.*lld", []FormatArg{{"%*.*d", "int", true}, {"%*.*d", "int", true}, {"%*.*d", "int", false}}, ""},
+		{"%", "", "", nil, `missing conversion at the end of "%"`},
+		{"%y", "", "", nil, "unknown conversion %y"},
+	}
+	for _, test := range tests {
+		f, err := ParseFormat(test.format)
+		if err != nil || test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%q: got error %v, want %q", test.format, err, test.err)
+			}
+			continue
+		}
+		if f.Go != test.goFmt || f.C != test.c || !reflect.DeepEqual(f.Args, test.args) {
+			t.Errorf("%q: got %q %q %v, want %q %q %v", test.format, f.Go, f.C, f.Args, test.goFmt, test.c, test.args)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+// Package stdlib describes the standard modules of myc: the types of their
+// functions for the type checker and their Go implementation for the
+// interpreter. The backends map them onto C and Go functions.
+package stdlib
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"strings"
+)
+
+// Func is a function of a standard module.
+type Func struct {
+	Params   []string
+	Variadic bool // the last parameter takes any number of arguments
+	Results  []string
+
+	// Format is set when the first argument is a printf format of C for the
+	// others, it must be a string literal
+	Format bool
+
+	// Impl is called by the interpreter, its types follow interp.Module.
+	// Functions that print take the output of the interpreter as their
+	// first parameter.
+	Impl interface{}
+}
+
+// Module is a standard module.
+type Module struct {
+	Name  string
+	Funcs map[string]*Func
+
+	// Restricted modules give access to the system the program runs on,
+	// the interpreter only imports them when it is allowed to
+	Restricted bool
+}
+
+// Modules are the standard modules by name.
+var Modules = map[string]*Module{
+	"stdio": {Name: "stdio", Funcs: map[string]*Func{
+		"printf": {Params: []string{"string", "any"}, Variadic: true, Format: true,
+			Impl: func(w io.Writer, format string, args ...interface{}) error {
+				f, err := ParseFormat(format)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(w, f.Go, cArgs(args)...)
+				return nil
+			}},
+		"puts": {Params: []string{"string"},
+			Impl: func(w io.Writer, s string) {
+				fmt.Fprintln(w, s)
+			}},
+		"sprintf": {Params: []string{"string", "any"}, Variadic: true, Results: []string{"string"}, Format: true,
+			Impl: func(format string, args ...interface{}) (string, error) {
+				f, err := ParseFormat(format)
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf(f.Go, cArgs(args)...), nil
+			}},
+	}},
+	"strings": {Name: "strings", Funcs: map[string]*Func{
+		"len":      {Params: []string{"string"}, Results: []string{"int"}, Impl: func(s string) int { return len(s) }},
+		"contains": {Params: []string{"string", "string"}, Results: []string{"bool"}, Impl: strings.Contains},
+		"index":    {Params: []string{"string", "string"}, Results: []string{"int"}, Impl: strings.Index},
+		"slice":    {Params: []string{"string", "int", "int"}, Results: []string{"string"}, Impl: slice},
+		"upper":    {Params: []string{"string"}, Results: []string{"string"}, Impl: strings.ToUpper},
+		"lower":    {Params: []string{"string"}, Results: []string{"string"}, Impl: strings.ToLower},
+		"repeat":   {Params: []string{"string", "int"}, Results: []string{"string"}, Impl: repeat},
+		"trim":     {Params: []string{"string"}, Results: []string{"string"}, Impl: strings.TrimSpace},
+	}},
+	"math": {Name: "math", Funcs: map[string]*Func{
+		"sqrt":  floatFunc(math.Sqrt),
+		"floor": floatFunc(math.Floor),
+		"ceil":  floatFunc(math.Ceil),
+		"abs":   floatFunc(math.Abs),
+		"sin":   floatFunc(math.Sin),
+		"cos":   floatFunc(math.Cos),
+		"exp":   floatFunc(math.Exp),
+		"log":   floatFunc(math.Log),
+		"pow":   {Params: []string{"float", "float"}, Results: []string{"float"}, Impl: math.Pow},
+	}},
+	"os": {Name: "os", Restricted: true, Funcs: map[string]*Func{
+		"readFile":  {Params: []string{"string"}, Results: []string{"string"}, Impl: readFile},
+		"writeFile": {Params: []string{"string", "string"}, Impl: writeFile},
+	}},
+}
+
+// Lookup returns the standard module imported by path, or nil. A path with
+// an extension like a C header is the module without it, so "stdio.h" is
+// stdio.
+func Lookup(path string) *Module {
+	if m, ok := Modules[path]; ok {
+		return m
+	}
+	if i := strings.Index(path, "."); i >= 0 && !strings.Contains(path, "/") {
+		return Modules[path[:i]]
+	}
+	return nil
+}
+
+func floatFunc(fn func(float64) float64) *Func {
+	return &Func{Params: []string{"float"}, Results: []string{"float"}, Impl: fn}
+}
+
+// cArgs returns the arguments of printf as C passes them, bools are ints.
+func cArgs(args []interface{}) []interface{} {
+	for i, a := range args {
+		if b, ok := a.(bool); ok {
+			if b {
+				args[i] = 1
+			} else {
+				args[i] = 0
+			}
+		}
+	}
+	return args
+}
+
+func slice(s string, i, j int) (string, error) {
+	if i < 0 || j < i || j > len(s) {
+		return "", fmt.Errorf("slice bounds out of range [%d:%d] with length %d", i, j, len(s))
+	}
+	return s[i:j], nil
+}
+
+func repeat(s string, n int) (string, error) {
+	if n < 0 {
+		return "", fmt.Errorf("negative repeat count %d", n)
+	}
+	return strings.Repeat(s, n), nil
+}
+
+func readFile(name string) (string, error) {
+	b, err := ioutil.ReadFile(name)
+	return string(b), err
+}
+
+func writeFile(name, data string) error {
+	return ioutil.WriteFile(name, []byte(data), 0666)
+}
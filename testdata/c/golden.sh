@@ -0,0 +1,47 @@
+#!/bin/sh
+# golden.sh checks the C backend. Every .myc file in this directory, or in
+# the directory given as argument, is compiled to C and compared with the
+# .c file next to it, the C file is then compiled with cc -Wall -Werror and
+# its output and exit status are compared with the .out file.
+#
+# Run it with -u to update the .c and .out files.
+set -e
+root=$(cd "$(dirname "$0")/../.." && pwd)
+
+update=false
+if [ "$1" = "-u" ]; then
+	update=true
+	shift
+fi
+
+tmp=$(mktemp -d)
+trap 'rm -rf "$tmp"' EXIT
+(cd "$root" && go build -o "$tmp/myc" ./cmd/myc)
+cd "${1:-$root/testdata/c}"
+
+status=0
+for src in *.myc; do
+	name=${src%.myc}
+	if ! "$tmp/myc" build -target c -o "$tmp/$name.c" "$src" >"$tmp/log" 2>&1; then
+		cat "$tmp/log"
+		echo "FAIL: $src"
+		status=1
+		continue
+	fi
+	${CC:-cc} -Wall -Werror -o "$tmp/$name" "$tmp/$name.c" -lm
+	set +e
+	"$tmp/$name" >"$tmp/$name.out"
+	echo "exit status $?" >>"$tmp/$name.out"
+	set -e
+	if $update; then
+		cp "$tmp/$name.c" "$tmp/$name.out" .
+		continue
+	fi
+	for f in "$name.c" "$name.out"; do
+		if ! diff -u "$f" "$tmp/$f"; then
+			echo "FAIL: $f"
+			status=1
+		fi
+	done
+done
+exit $status
@@ -0,0 +1,159 @@
+package token
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Pos is a compact source position, an offset into a FileSet.
+// The zero value NoPos is no position.
+type Pos int
+
+const NoPos Pos = 0
+
+func (p Pos) IsValid() bool {
+	return p != NoPos
+}
+
+// Position is a position that can be shown to the user.
+// Line and Column start at 1, Column counts runes.
+type Position struct {
+	Filename string
+	Offset   int
+	Line     int
+	Column   int
+}
+
+func (pos Position) IsValid() bool {
+	return pos.Line > 0
+}
+
+func (pos Position) String() string {
+	var s = pos.Filename
+	if pos.IsValid() {
+		if s != "" {
+			s += ":"
+		}
+		s += fmt.Sprintf("%d:%d", pos.Line, pos.Column)
+	}
+	if s == "" {
+		s = "-"
+	}
+	return s
+}
+
+// File is a source file of a FileSet.
+type File struct {
+	name  string
+	base  int
+	size  int
+	lines []int // offsets of the first byte of every line
+	cont  []int // offsets of UTF-8 continuation bytes, they are not columns
+}
+
+func (f *File) Name() string {
+	return f.name
+}
+
+func (f *File) Base() int {
+	return f.base
+}
+
+func (f *File) Size() int {
+	return f.size
+}
+
+// AddLine records that a line starts at offset. Lines must be added in order.
+func (f *File) AddLine(offset int) {
+	if n := len(f.lines); (n == 0 || f.lines[n-1] < offset) && offset < f.size {
+		f.lines = append(f.lines, offset)
+	}
+}
+
+// AddCont records that the byte at offset continues a multi-byte rune.
+// Offsets must be added in order.
+func (f *File) AddCont(offset int) {
+	if n := len(f.cont); n == 0 || f.cont[n-1] < offset {
+		f.cont = append(f.cont, offset)
+	}
+}
+
+// Grow records that the file has at least size bytes. Files read from a
+// stream grow as they are read, only the last file of a set may grow.
+func (f *File) Grow(size int) {
+	if size > f.size {
+		f.size = size
+	}
+}
+
+// Pos returns the Pos of the byte at offset.
+func (f *File) Pos(offset int) Pos {
+	if offset > f.size {
+		panic("illegal file offset")
+	}
+	return Pos(f.base + offset)
+}
+
+// Offset returns the offset of p in f.
+func (f *File) Offset(p Pos) int {
+	if int(p) < f.base || int(p) > f.base+f.size {
+		panic("illegal Pos value")
+	}
+	return int(p) - f.base
+}
+
+func (f *File) Position(p Pos) Position {
+	if !p.IsValid() {
+		return Position{}
+	}
+	var offset = f.Offset(p)
+	var i = sort.Search(len(f.lines), func(i int) bool { return f.lines[i] > offset }) - 1
+	var line, start = 1, 0
+	if i >= 0 {
+		line, start = i+1, f.lines[i]
+	}
+	// continuation bytes between the line start and offset are no columns
+	var cont = sort.SearchInts(f.cont, offset) - sort.SearchInts(f.cont, start)
+	return Position{Filename: f.name, Offset: offset, Line: line, Column: offset - start - cont + 1}
+}
+
+// FileSet is a set of source files, every file has its own range of Pos
+// values so that a Pos alone says which file it belongs to.
+type FileSet struct {
+	base  int
+	files []*File
+}
+
+func NewFileSet() *FileSet {
+	return &FileSet{base: 1}
+}
+
+// AddFile adds a file of the given size, its lines are added by the lexer.
+// The size of a file that is read from a stream can be 0, it grows as the
+// lexer reads it.
+func (s *FileSet) AddFile(name string, size int) *File {
+	if n := len(s.files); n > 0 {
+		// +1 so that the end of a file has a position of its own
+		s.base = s.files[n-1].base + s.files[n-1].size + 1
+	}
+	var f = &File{name: name, base: s.base, size: size, lines: []int{0}}
+	s.files = append(s.files, f)
+	return f
+}
+
+// File returns the file that contains p, or nil.
+func (s *FileSet) File(p Pos) *File {
+	for _, f := range s.files {
+		if int(p) >= f.base && int(p) <= f.base+f.size {
+			return f
+		}
+	}
+	return nil
+}
+
+func (s *FileSet) Position(p Pos) Position {
+	if f := s.File(p); f != nil {
+		return f.Position(p)
+	}
+	return Position{}
+}
@@ -0,0 +1,195 @@
+// Package token defines the tokens of myc and the positions in source files.
+package token
+
+import (
+	"fmt"
+	"strings"
+)
+
+type TokenType int
+
+const (
+	TokenEOF TokenType = iota
+	TokenEnter
+	TokenPlus
+	TokenMinus
+	TokenMul
+	TokenDiv
+	TokenMod
+	TokenID
+
+	TokenNumber
+	TokenString
+
+	TokenLParen
+	TokenRParen
+	TokenLBrace
+	TokenRBrace
+	TokenLBracket
+	TokenRBracket
+	TokenAssign
+	TokenComma
+	TokenColon
+	TokenSemicolon
+	TokenDot
+	TokenRange
+
+	TokenIf
+	TokenVar
+	TokenElse
+	TokenThen
+	TokenAs
+	TokenImport
+	TokenAndSlower
+	TokenOrSlower
+	TokenNotSlower
+	TokenFunction
+	TokenReturn
+	TokenWhile
+	TokenFor
+	TokenIn
+	TokenBreak
+	TokenContinue
+	TokenTrue
+	TokenFalse
+
+	TokenAnd
+	TokenOr
+	TokenNot
+	TokenCompare
+
+	TokenOpBit
+	TokenOpAnd
+	TokenUnaryOp
+)
+
+var tokenNames = [...]string{
+	TokenEOF:    "EOF",
+	TokenEnter:  "newline",
+	TokenPlus:   "'+'",
+	TokenMinus:  "'-'",
+	TokenMul:    "'*'",
+	TokenDiv:    "'/'",
+	TokenMod:    "'%'",
+	TokenID:     "identifier",
+	TokenNumber: "number",
+	TokenString: "string",
+
+	TokenLParen: "'('",
+	TokenRParen: "')'",
+	TokenLBrace: "'{'",
+	TokenRBrace: "'}'",
+
+	TokenLBracket:  "'['",
+	TokenRBracket:  "']'",
+	TokenAssign:    "assignment",
+	TokenComma:     "','",
+	TokenColon:     "':'",
+	TokenSemicolon: "';'",
+	TokenDot:       "'.'",
+	TokenRange:     "'..'",
+
+	TokenIf:        "'if'",
+	TokenVar:       "'var'",
+	TokenElse:      "'else'",
+	TokenThen:      "'then'",
+	TokenAs:        "'as'",
+	TokenImport:    "'import'",
+	TokenAndSlower: "'and'",
+	TokenOrSlower:  "'or'",
+	TokenNotSlower: "'not'",
+	TokenFunction:  "'func'",
+	TokenReturn:    "'return'",
+	TokenWhile:     "'while'",
+	TokenFor:       "'for'",
+	TokenIn:        "'in'",
+	TokenBreak:     "'break'",
+	TokenContinue:  "'continue'",
+	TokenTrue:      "'true'",
+	TokenFalse:     "'false'",
+
+	TokenAnd:     "'&&'",
+	TokenOr:      "'||'",
+	TokenNot:     "'!'",
+	TokenCompare: "comparison",
+
+	TokenOpBit:   "operator",
+	TokenOpAnd:   "'&'",
+	TokenUnaryOp: "operator",
+}
+
+func (t TokenType) String() string {
+	if int(t) < len(tokenNames) && tokenNames[t] != "" {
+		return tokenNames[t]
+	}
+	return fmt.Sprintf("TokenType(%d)", int(t))
+}
+
+var KeyWords = map[string]*Token{
+	"var":      {Type: TokenVar, Value: "VAR"},
+	"if":       {Type: TokenIf, Value: "IF"},
+	"then":     {Type: TokenThen, Value: "THEN"},
+	"else":     {Type: TokenElse, Value: "ELSE"},
+	"and":      {Type: TokenAndSlower, Value: "AND"},
+	"or":       {Type: TokenOrSlower, Value: "OR"},
+	"not":      {Type: TokenNotSlower, Value: "NOT"},
+	"func":     {Type: TokenFunction, Value: "FUNC"},
+	"return":   {Type: TokenReturn, Value: "RETURN"},
+	"while":    {Type: TokenWhile, Value: "WHILE"},
+	"for":      {Type: TokenFor, Value: "FOR"},
+	"in":       {Type: TokenIn, Value: "IN"},
+	"break":    {Type: TokenBreak, Value: "BREAK"},
+	"continue": {Type: TokenContinue, Value: "CONTINUE"},
+	"true":     {Type: TokenTrue, Value: "TRUE"},
+	"false":    {Type: TokenFalse, Value: "FALSE"},
+	"as":       {Type: TokenAs, Value: "AS"},
+	"import":   {Type: TokenImport, Value: "IMPORT"},
+}
+
+type Token struct {
+	Type  TokenType
+	Value string
+
+	Line   int
+	Offset int
+	Pos    Pos    // start of the token
+	End    Pos    // position just after the token
+	Lit    string // the source text of a string literal
+
+	// comments in front of the token. Comments at the end of a line go
+	// with the newline token after them.
+	Lead []Comment
+}
+
+// Comment is a // or /* */ comment, Text includes the comment markers.
+type Comment struct {
+	Text string
+	Line int // the line the comment starts on
+	Pos  Pos
+	End  Pos
+}
+
+// Lines returns the number of lines the comment spans.
+func (c Comment) Lines() int {
+	return strings.Count(c.Text, "\n") + 1
+}
+
+func (t Token) String() string {
+	if t.Type == TokenString {
+		return fmt.Sprintf("(%d:%d %v:%q)", t.Line, t.Offset, t.Type, t.Value)
+	}
+	return fmt.Sprintf("(%d:%d %v:%v)", t.Line, t.Offset, t.Type, t.Value)
+}
+
+// Describe returns the token for use in messages.
+func (t *Token) Describe() string {
+	switch t.Type {
+	case TokenID, TokenNumber:
+		return fmt.Sprintf("%v %s", t.Type, t.Value)
+	case TokenString:
+		return fmt.Sprintf("%v %q", t.Type, t.Value)
+	case TokenAssign, TokenCompare, TokenOpBit, TokenUnaryOp:
+		return "'" + t.Value + "'"
+	}
+	return t.Type.String()
+}
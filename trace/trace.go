@@ -0,0 +1,109 @@
+// Package trace lets the lexer, the parser and the interpreter report what
+// they do.
+package trace
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"myc/ast"
+	"myc/token"
+)
+
+// Kind is the kind of a traced event.
+type Kind int
+
+const (
+	Lex   Kind = 1 << iota // the lexer read a token
+	Parse                  // the parser ate a token
+	Exec                   // the interpreter executes a node
+)
+
+var traceNames = [...]string{"lex", "parse", "exec"}
+
+// String returns the names of the kinds in k separated by commas.
+func (k Kind) String() string {
+	var names []string
+	for i, name := range traceNames {
+		if k&(1<<uint(i)) != 0 {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// Set sets k to a comma separated list of event kinds, such as
+// "lex,parse,exec". It makes *Kind a flag.Value.
+func (k *Kind) Set(s string) error {
+	var kinds Kind
+	for _, name := range strings.Split(s, ",") {
+		var i = 0
+		for i < len(traceNames) && traceNames[i] != strings.TrimSpace(name) {
+			i++
+		}
+		if i == len(traceNames) {
+			return fmt.Errorf("unknown trace event %q, want lex, parse or exec", name)
+		}
+		kinds |= 1 << uint(i)
+	}
+	*k = kinds
+	return nil
+}
+
+// Event is an event of the lexer, the parser or the interpreter.
+// Lex and parse events have a Token, exec events have a Node.
+type Event struct {
+	Kind  Kind
+	Token *token.Token
+	Node  ast.AST
+}
+
+// Pos returns the position of the token or node of e.
+func (e Event) Pos() token.Pos {
+	if e.Token != nil {
+		return e.Token.Pos
+	}
+	return ast.PosOf(e.Node)
+}
+
+// Tracer observes the events of the lexer, the parser and the interpreter.
+// They do not trace anything unless a tracer is set.
+type Tracer interface {
+	Trace(e Event)
+}
+
+// textTracer writes the events of kinds as lines of text, one per event.
+type textTracer struct {
+	w     io.Writer
+	file  *token.File
+	kinds Kind
+}
+
+// New returns a tracer writing the events of kinds in file to w, or
+// nil if kinds is empty.
+func New(w io.Writer, file *token.File, kinds Kind) Tracer {
+	if kinds == 0 {
+		return nil
+	}
+	return &textTracer{w: w, file: file, kinds: kinds}
+}
+
+// maxTraceNode limits the length of the nodes written by textTracer.
+const maxTraceNode = 72
+
+func (t *textTracer) Trace(e Event) {
+	if t.kinds&e.Kind == 0 {
+		return
+	}
+	var what string
+	if e.Token != nil {
+		what = e.Token.Describe()
+	} else {
+		what = strings.Replace(fmt.Sprint(e.Node), "\n", `\n`, -1)
+		if len(what) > maxTraceNode {
+			what = what[:maxTraceNode-3] + "..."
+		}
+	}
+	fmt.Fprintf(t.w, "%-5s %v %s\n", e.Kind, t.file.Position(e.Pos()), what)
+}
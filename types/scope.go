@@ -0,0 +1,61 @@
+package types
+
+// Object is a name declared in a Scope.
+type Object struct {
+	Name string
+	Type string
+	Sig  *Signature // type of a function, nil when it is not known
+}
+
+// Scope maps the names declared in a block to their types. It is the symbol
+// table of the type checker and the backends, the interpreter keeps values
+// in a SymbolTable.
+type Scope struct {
+	outer   *Scope
+	objects map[string]*Object
+}
+
+// NewScope returns a scope inside outer, which may be nil.
+func NewScope(outer *Scope) *Scope {
+	return &Scope{
+		outer:   outer,
+		objects: make(map[string]*Object),
+	}
+}
+
+// Lookup returns the object name refers to in s or the scopes around it,
+// or nil.
+func (s *Scope) Lookup(name string) *Object {
+	for ; s != nil; s = s.outer {
+		if obj, ok := s.objects[name]; ok {
+			return obj
+		}
+	}
+	return nil
+}
+
+// Insert declares name with type t in s and returns its object. A name that
+// is already declared in s keeps its object.
+func (s *Scope) Insert(name, t string) *Object {
+	if obj, ok := s.objects[name]; ok {
+		return obj
+	}
+	var obj = &Object{Name: name, Type: t}
+	s.objects[name] = obj
+	return obj
+}
+
+// Add declares obj in s, it replaces an object of the same name.
+func (s *Scope) Add(obj *Object) {
+	s.objects[obj.Name] = obj
+}
+
+// Outer returns the scope around s, nil for the outermost scope.
+func (s *Scope) Outer() *Scope {
+	return s.outer
+}
+
+// Local returns the object declared as name in s itself, or nil.
+func (s *Scope) Local(name string) *Object {
+	return s.objects[name]
+}
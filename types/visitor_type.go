@@ -0,0 +1,830 @@
+// Package types checks the types of myc programs for the backends.
+package types
+
+import (
+	"strconv"
+	"strings"
+
+	"myc/ast"
+	"myc/diag"
+	"myc/stdlib"
+)
+
+// Types of the checker are written as strings like in the source.
+// Any is used where the type is not known, it is compatible with every
+// other type and is checked at run time by the interpreter.
+const (
+	Int    = "int"
+	Float  = "float"
+	String = "string"
+	Bool   = "bool"
+	Func   = "func"
+	Any    = "any"
+)
+
+// Signature is the type of a function.
+type Signature struct {
+	Params  []string
+	Results []string
+
+	// the results are inferred from the first return of the body and are
+	// not known until it has been checked
+	infer    bool
+	Inferred bool
+}
+
+func (sig *Signature) String() string {
+	var s = "func(" + strings.Join(sig.Params, ", ") + ")"
+	switch len(sig.Results) {
+	case 0:
+		return s
+	case 1:
+		return s + " " + sig.Results[0]
+	}
+	return s + " (" + strings.Join(sig.Results, ", ") + ")"
+}
+
+// TypeInfo is the result of the type checker. It records the type of every
+// expression and declared variable and the signature of every function,
+// by the position of the node.
+type TypeInfo struct {
+	types map[ast.Span]string
+	sigs  map[ast.Span]*Signature
+}
+
+func spanOf(node ast.AST) ast.Span {
+	return ast.Span{Start: node.Pos(), Stop: node.End()}
+}
+
+// TypeOf returns the type of an expression or declared variable, or "" if
+// it is not known. A call with several results has a tuple type like
+// (int, string), one without results has none.
+func (info *TypeInfo) TypeOf(node ast.AST) string {
+	if info == nil || node == nil {
+		return ""
+	}
+	return info.types[spanOf(node)]
+}
+
+// SignatureOf returns the signature of a function declaration.
+func (info *TypeInfo) SignatureOf(fn ast.ASTFunction) *Signature {
+	if info == nil {
+		return nil
+	}
+	return info.sigs[spanOf(fn)]
+}
+
+func NewTypeCheckVisitor(node ast.AST) *TypeCheckVisitor {
+	return &TypeCheckVisitor{
+		ast: node,
+	}
+}
+
+// TypeCheckVisitor checks the types of a program before it is compiled.
+type TypeCheckVisitor struct {
+	ast    ast.AST
+	st     *Scope
+	global *Scope
+	info   *TypeInfo
+	diags  diag.Diagnostics
+
+	fn      *Signature // function being checked, nil at the top level
+	loops   int        // number of loops around the statement
+	pending map[*Object]ast.ASTFunction
+
+	// the global names and imports before the last Check, for Rollback
+	saved        map[string]*Object
+	savedImports map[string]*stdlib.Module
+
+	// import name -> standard module, nil for other imports
+	imports map[string]*stdlib.Module
+}
+
+// Exec checks the program, the errors are returned as Diagnostics.
+func (tc *TypeCheckVisitor) Exec() error {
+	tc.init()
+	tc.exec(tc.ast)
+	return tc.diags.Err()
+}
+
+// Check checks another program in the global scope of the programs checked
+// by Check before it, like the lines of the REPL. The types of all of them
+// are in Info.
+func (tc *TypeCheckVisitor) Check(node ast.AST) error {
+	if tc.global == nil {
+		tc.init()
+	}
+	tc.st, tc.fn, tc.loops = tc.global, nil, 0
+	tc.diags = nil
+	tc.saved = make(map[string]*Object)
+	for name, obj := range tc.global.objects {
+		tc.saved[name] = obj
+	}
+	tc.savedImports = make(map[string]*stdlib.Module)
+	for name, m := range tc.imports {
+		tc.savedImports[name] = m
+	}
+	tc.exec(node)
+	return tc.diags.Err()
+}
+
+// Rollback undoes the declarations of the last Check, for a program that
+// failed when it was run.
+func (tc *TypeCheckVisitor) Rollback() {
+	if tc.saved == nil {
+		return
+	}
+	tc.global.objects, tc.imports = tc.saved, tc.savedImports
+	tc.saved, tc.savedImports = nil, nil
+}
+
+func (tc *TypeCheckVisitor) init() {
+	tc.st = NewScope(nil)
+	tc.global = tc.st
+	tc.info = &TypeInfo{
+		types: make(map[ast.Span]string),
+		sigs:  make(map[ast.Span]*Signature),
+	}
+	tc.imports = make(map[string]*stdlib.Module)
+	tc.pending = make(map[*Object]ast.ASTFunction)
+}
+
+// Info returns the types found by Exec.
+func (tc *TypeCheckVisitor) Info() *TypeInfo {
+	return tc.info
+}
+
+func (tc *TypeCheckVisitor) errorf(node ast.AST, format string, args ...interface{}) {
+	tc.diags = append(tc.diags, diag.Errorf(ast.PosOf(node), format, args...))
+}
+
+// record stores the type of node and returns it.
+func (tc *TypeCheckVisitor) record(node ast.AST, t string) string {
+	if t != "" {
+		tc.info.types[spanOf(node)] = t
+	}
+	return t
+}
+
+func (tc *TypeCheckVisitor) exec(node ast.AST) string {
+	switch node := node.(type) {
+	case ast.ASTProject:
+		for _, i := range node.Imports {
+			var name = i.Path[strings.LastIndex(i.Path, "/")+1:]
+			if j := strings.Index(name, "."); j >= 0 {
+				name = name[:j]
+			}
+			tc.imports[name] = stdlib.Lookup(i.Path)
+		}
+		// functions can be called before they are declared, their bodies
+		// are checked after the top level unless they are called earlier
+		var list []ast.AST
+		if stmt, ok := node.StmtList.(ast.ASTStmt); ok {
+			list = stmt.List
+		}
+		var funcs []*Object
+		for _, a := range list {
+			if fn, ok := a.(ast.ASTFunction); ok {
+				if s := tc.declareFunc(fn); s != nil {
+					tc.pending[s] = fn
+					funcs = append(funcs, s)
+				}
+			}
+		}
+		for _, a := range list {
+			if _, ok := a.(ast.ASTFunction); !ok {
+				tc.exec(a)
+			}
+		}
+		for _, s := range funcs {
+			tc.checkPending(s)
+		}
+		return ""
+	case ast.ASTStmt:
+		var prev = tc.st
+		tc.st = NewScope(prev)
+		for _, a := range node.List {
+			tc.exec(a)
+		}
+		tc.st = prev
+		return ""
+	case ast.ASTAssign:
+		tc.assign(node)
+		return ""
+	case ast.ASTBranch:
+		tc.cond(node.Cond)
+		tc.body(node.Then)
+		tc.body(node.Else)
+		return ""
+	case ast.ASTWhile:
+		tc.cond(node.Cond)
+		tc.loopBody(node.Body)
+		return ""
+	case ast.ASTFor:
+		var prev = tc.st
+		tc.st = NewScope(prev)
+		tc.exec(node.Init)
+		if node.Cond != nil {
+			tc.cond(node.Cond)
+		}
+		tc.exec(node.Post)
+		tc.loopBody(node.Body)
+		tc.st = prev
+		return ""
+	case ast.ASTRange:
+		for _, a := range []ast.AST{node.From, node.To} {
+			if t := tc.value(a); t != Int && t != Any {
+				tc.errorf(a, "cannot range over %s (%s)", ast.ExprString(a), t)
+			}
+		}
+		var prev = tc.st
+		tc.st = NewScope(prev)
+		tc.st.Insert(node.Key.Name, Int)
+		tc.record(node.Key, Int)
+		tc.loopBody(node.Body)
+		tc.st = prev
+		return ""
+	case ast.ASTBreak:
+		if tc.loops == 0 {
+			tc.errorf(node, "break is not in a loop")
+		}
+		return ""
+	case ast.ASTContinue:
+		if tc.loops == 0 {
+			tc.errorf(node, "continue is not in a loop")
+		}
+		return ""
+	case ast.ASTFunction:
+		if s := tc.declareFunc(node); s != nil {
+			tc.function(node, s.Sig)
+		}
+		return ""
+	case ast.ASTReturn:
+		tc.returns(node)
+		return ""
+	case ast.ASTEmpty, ast.ASTError, nil:
+		return ""
+	case ast.ASTCallFunc:
+		var results = tc.call(node)
+		switch len(results) {
+		case 0:
+			return ""
+		case 1:
+			return tc.record(node, results[0])
+		}
+		return tc.record(node, "("+strings.Join(results, ", ")+")")
+	}
+	return tc.expr(node)
+}
+
+// body checks the branch of an if in its own scope.
+func (tc *TypeCheckVisitor) body(node ast.AST) {
+	var prev = tc.st
+	tc.st = NewScope(prev)
+	tc.exec(node)
+	tc.st = prev
+}
+
+// loopBody checks the body of a loop.
+func (tc *TypeCheckVisitor) loopBody(node ast.AST) {
+	tc.loops++
+	tc.body(node)
+	tc.loops--
+}
+
+// declareFunc adds the function to the current scope.
+func (tc *TypeCheckVisitor) declareFunc(node ast.ASTFunction) *Object {
+	var sig = &Signature{infer: len(node.Results) == 0}
+	for _, p := range node.Params {
+		tc.checkType(p, p.Type)
+		sig.Params = append(sig.Params, paramType(p))
+	}
+	for _, r := range node.Results {
+		tc.checkType(r, r.Type)
+		sig.Results = append(sig.Results, r.Type)
+	}
+	tc.info.sigs[spanOf(node)] = sig
+	if tc.st.Local(node.Name.Name) != nil {
+		tc.errorf(node.Name, "%s redeclared in this block", node.Name.Name)
+		return nil
+	}
+	var s = tc.st.Insert(node.Name.Name, Func)
+	s.Sig = sig
+	tc.record(node.Name, Func)
+	return s
+}
+
+// checkPending checks the body of a top level function if that has not
+// been done yet.
+func (tc *TypeCheckVisitor) checkPending(s *Object) {
+	fn, ok := tc.pending[s]
+	if !ok {
+		return
+	}
+	delete(tc.pending, s)
+	var prev = tc.st
+	tc.st = tc.global
+	tc.function(fn, s.Sig)
+	tc.st = prev
+}
+
+func (tc *TypeCheckVisitor) function(node ast.ASTFunction, sig *Signature) {
+	var prev, prevFn, prevLoops = tc.st, tc.fn, tc.loops
+	tc.st = NewScope(prev)
+	tc.fn, tc.loops = sig, 0
+	for i, p := range node.Params {
+		if tc.st.Local(p.Name) != nil {
+			tc.errorf(p, "duplicate argument %s", p.Name)
+		}
+		tc.st.Insert(p.Name, sig.Params[i])
+		tc.record(p, sig.Params[i])
+	}
+	tc.exec(node.Body)
+	// a function without return has no results
+	sig.Inferred = true
+	if len(sig.Results) > 0 && !terminates(node.Body) {
+		tc.diags = append(tc.diags, diag.Errorf(node.Body.End()-1, "missing return"))
+	}
+	tc.st, tc.fn, tc.loops = prev, prevFn, prevLoops
+}
+
+// terminates reports whether a function body ends in a return on every
+// path, or in a loop that is only left by a return.
+func terminates(node ast.AST) bool {
+	switch node := node.(type) {
+	case ast.ASTReturn:
+		return true
+	case ast.ASTStmt:
+		for i := len(node.List) - 1; i >= 0; i-- {
+			if _, ok := node.List[i].(ast.ASTEmpty); !ok {
+				return terminates(node.List[i])
+			}
+		}
+	case ast.ASTBranch:
+		return node.Else != nil && terminates(node.Then) && terminates(node.Else)
+	case ast.ASTWhile:
+		var b, ok = node.Cond.(ast.ASTBool)
+		return ok && b.Value && !breaks(node.Body)
+	case ast.ASTFor:
+		return node.Cond == nil && !breaks(node.Body)
+	}
+	return false
+}
+
+// breaks reports whether a break in node leaves the loop around it.
+func breaks(node ast.AST) bool {
+	switch node := node.(type) {
+	case ast.ASTBreak:
+		return true
+	case ast.ASTStmt:
+		for _, a := range node.List {
+			if breaks(a) {
+				return true
+			}
+		}
+	case ast.ASTBranch:
+		return breaks(node.Then) || breaks(node.Else)
+	}
+	// a break in a nested loop or function leaves that
+	return false
+}
+
+func (tc *TypeCheckVisitor) returns(node ast.ASTReturn) {
+	var types []string
+	for _, a := range node.Exprs {
+		types = append(types, tc.value(a))
+	}
+	var sig = tc.fn
+	if sig == nil {
+		return // return from the program
+	}
+	if sig.infer && !sig.Inferred {
+		sig.Results = types
+		sig.Inferred = true
+		return
+	}
+	if len(types) != len(sig.Results) {
+		tc.errorf(node, "wrong number of return values: have %d, want %d", len(types), len(sig.Results))
+		return
+	}
+	for i, t := range types {
+		if !assignable(sig.Results[i], t) {
+			tc.errorf(node.Exprs[i], "cannot use %s (%s) as %s value in return statement", ast.ExprString(node.Exprs[i]), t, sig.Results[i])
+		}
+	}
+}
+
+// call checks a call and returns the types of its results.
+func (tc *TypeCheckVisitor) call(node ast.ASTCallFunc) []string {
+	var args []string
+	for _, a := range node.Args {
+		args = append(args, tc.value(a))
+	}
+	var name = node.Name.Name
+	if m, fn, ok := tc.imported(name); ok {
+		if m == nil {
+			// functions of other imports are not known to the checker
+			return []string{Any}
+		}
+		f, ok := m.Funcs[fn]
+		if !ok {
+			tc.errorf(node.Name, "undefined: %s", name)
+			return []string{Any}
+		}
+		tc.args(node, args, f.Params, f.Variadic)
+		if f.Format {
+			tc.format(node, args)
+		}
+		return f.Results
+	}
+	var s = tc.st.Lookup(name)
+	if s == nil {
+		tc.errorf(node.Name, "undefined: %s", name)
+		return []string{Any}
+	}
+	if s.Type == Any || s.Sig == nil && strings.HasPrefix(s.Type, "func(") {
+		return []string{Any}
+	}
+	if s.Sig == nil {
+		tc.errorf(node.Name, "cannot call non-function %s (%s)", name, s.Type)
+		return []string{Any}
+	}
+	tc.record(node.Name, Func)
+	var sig = s.Sig
+	tc.args(node, args, sig.Params, false)
+	tc.checkPending(s)
+	if sig.infer && !sig.Inferred {
+		// a recursive call, the results are not known yet
+		return []string{Any}
+	}
+	return sig.Results
+}
+
+// args checks the types of the arguments of a call. The last parameter of
+// a variadic function takes the remaining arguments.
+func (tc *TypeCheckVisitor) args(node ast.ASTCallFunc, args, params []string, variadic bool) {
+	if variadic && len(args) < len(params)-1 || !variadic && len(args) != len(params) {
+		var want = strconv.Itoa(len(params))
+		if variadic {
+			want = strconv.Itoa(len(params)-1) + " or more"
+		}
+		tc.errorf(node, "wrong number of arguments in call to %s: have %d, want %s", node.Name.Name, len(args), want)
+		return
+	}
+	for i, t := range args {
+		var p = params[minInt(i, len(params)-1)]
+		if !assignable(p, t) {
+			tc.errorf(node.Args[i], "cannot use %s (%s) as %s value in argument to %s", ast.ExprString(node.Args[i]), t, p, node.Name.Name)
+		}
+	}
+}
+
+// format checks the arguments of a call to a standard function like
+// stdio.printf against its format, the first argument. The format must be
+// a string literal.
+func (tc *TypeCheckVisitor) format(node ast.ASTCallFunc, args []string) {
+	if len(node.Args) == 0 {
+		return
+	}
+	s, ok := node.Args[0].(ast.ASTString)
+	if !ok {
+		tc.errorf(node.Args[0], "format of %s must be a string literal", node.Name.Name)
+		return
+	}
+	f, err := stdlib.ParseFormat(s.Value)
+	if err != nil {
+		tc.errorf(node.Args[0], "%s format: %v", node.Name.Name, err)
+		return
+	}
+	if len(f.Args) != len(args)-1 {
+		tc.errorf(node, "%s format reads %d arguments, but the call has %d", node.Name.Name, len(f.Args), len(args)-1)
+		return
+	}
+	for i, a := range f.Args {
+		// ints take bools like in C, but floats take only floats
+		var t = args[i+1]
+		if t != a.Type && t != Any && !(a.Type == Int && t == Bool) {
+			tc.errorf(node.Args[i+1], "%s format %s has argument %s of wrong type %s", node.Name.Name, a.Conv, ast.ExprString(node.Args[i+1]), t)
+		}
+	}
+}
+
+// imported splits a name qualified by an import into the module and the
+// name in it, m is nil when the import is not a standard module.
+func (tc *TypeCheckVisitor) imported(name string) (m *stdlib.Module, fn string, ok bool) {
+	var i = strings.Index(name, ".")
+	if i < 0 {
+		return nil, "", false
+	}
+	m, ok = tc.imports[name[:i]]
+	return m, name[i+1:], ok
+}
+
+func (tc *TypeCheckVisitor) assign(node ast.ASTAssign) {
+	var right []string
+	if call, ok := node.Right[0].(ast.ASTCallFunc); ok && len(node.Right) == 1 && len(node.Left) > 1 {
+		// exp. var a,b=f()
+		right = tc.call(call)
+	} else {
+		for _, a := range node.Right {
+			right = append(right, tc.value(a))
+		}
+	}
+	if len(right) == 1 { // exp. var a,b,c=1
+		for len(right) < len(node.Left) {
+			right = append(right, right[0])
+		}
+	}
+	if len(node.Left) != len(right) {
+		tc.errorf(node, "assignment mismatch: %d variables but %d values", len(node.Left), len(right))
+		right = make([]string, len(node.Left))
+		for i := range right {
+			right[i] = Any
+		}
+	}
+
+	for i, v := range node.Left {
+		var t = right[i]
+		if node.IsDefined {
+			if node.Op != "=" {
+				tc.errorf(node, "unexpected %s in var declaration", node.Op)
+			}
+			if v.Type != "" {
+				if !tc.checkType(v, v.Type) {
+					t = Any
+				} else if !assignable(v.Type, t) {
+					tc.errorf(node.Right[minInt(i, len(node.Right)-1)], "cannot use %s value as %s in declaration of %s", t, v.Type, v.Name)
+					t = v.Type
+				} else {
+					t = v.Type
+				}
+			}
+			if tc.st.Local(v.Name) != nil {
+				tc.errorf(v, "%s redeclared in this block", v.Name)
+			}
+			tc.st.Insert(v.Name, t).Sig = tc.funcValue(node.Right[minInt(i, len(node.Right)-1)])
+			tc.record(v, t)
+			continue
+		}
+
+		var s = tc.st.Lookup(v.Name)
+		if s == nil {
+			if _, _, ok := tc.imported(v.Name); !ok {
+				tc.errorf(v, "undefined: %s", v.Name)
+			}
+			continue
+		}
+		if s.Sig != nil {
+			tc.errorf(v, "cannot assign to %s (func)", v.Name)
+			continue
+		}
+		if node.Op != "=" {
+			t = tc.binary(node, strings.TrimSuffix(node.Op, "="), s.Type, t)
+		}
+		if !assignable(s.Type, t) {
+			tc.errorf(v, "cannot use %s value as %s in assignment to %s", t, s.Type, v.Name)
+		}
+		tc.record(v, s.Type)
+	}
+}
+
+// value checks an expression that must have a single value.
+// cond checks the condition of an if, while or for statement.
+func (tc *TypeCheckVisitor) cond(node ast.AST) {
+	if t := tc.value(node); t != Bool && t != Any {
+		tc.errorf(node, "non-bool %s (%s) used as condition", ast.ExprString(node), t)
+	}
+}
+
+func (tc *TypeCheckVisitor) value(node ast.AST) string {
+	if call, ok := node.(ast.ASTCallFunc); ok {
+		var results = tc.call(call)
+		switch len(results) {
+		case 0:
+			tc.errorf(node, "%s (no value) used as value", ast.ExprString(node))
+			return Any
+		case 1:
+			return tc.record(node, results[0])
+		}
+		tc.errorf(node, "multiple-value %s in single-value context", ast.ExprString(node))
+		return Any
+	}
+	return tc.expr(node)
+}
+
+func (tc *TypeCheckVisitor) expr(node ast.AST) string {
+	switch node := node.(type) {
+	case ast.ASTNumber:
+		return tc.record(node, node.Kind)
+	case ast.ASTString:
+		return tc.record(node, String)
+	case ast.ASTBool:
+		return tc.record(node, Bool)
+	case ast.ASTVariable:
+		var s = tc.st.Lookup(node.Name)
+		if s == nil {
+			if m, fn, ok := tc.imported(node.Name); ok {
+				if m != nil && m.Funcs[fn] == nil {
+					tc.errorf(node, "undefined: %s", node.Name)
+				}
+				return tc.record(node, Any)
+			}
+			tc.errorf(node, "undefined: %s", node.Name)
+			return Any
+		}
+		if s.Sig != nil {
+			// the results of the function are needed for its type
+			tc.checkPending(s)
+			if s.Sig.infer && !s.Sig.Inferred {
+				return tc.record(node, Any)
+			}
+			return tc.record(node, s.Sig.String())
+		}
+		return tc.record(node, s.Type)
+	case ast.ASTUnaryOp:
+		var t = tc.value(node.Operand)
+		switch node.Op {
+		case "-", "+":
+			if t != Int && t != Float && t != Any {
+				tc.errorf(node, "invalid operation: operator %s not defined on %s (%s)", node.Op, ast.ExprString(node.Operand), t)
+				return Any
+			}
+			return tc.record(node, t)
+		case "~":
+			if t != Int && t != Any {
+				tc.errorf(node, "invalid operation: operator %s not defined on %s (%s)", node.Op, ast.ExprString(node.Operand), t)
+				return Any
+			}
+			return tc.record(node, Int)
+		case "!":
+			return tc.record(node, Bool)
+		}
+		tc.errorf(node, "invalid operation: unary operator %s is not supported", node.Op)
+		return Any
+	case ast.ASTBinaryOp:
+		if node.Op == "as" {
+			var t = tc.value(node.Left)
+			v, ok := node.Right.(ast.ASTVariable)
+			if !ok {
+				tc.errorf(node.Right, "%s is not a type", ast.ExprString(node.Right))
+				return Any
+			}
+			switch v.Name {
+			case Int, Float, String, Bool, Any:
+			default:
+				tc.errorf(node.Right, "%s is not a type", v.Name)
+				return Any
+			}
+			if !convertible(v.Name, t) {
+				tc.errorf(node, "cannot convert %s (%s) to %s", ast.ExprString(node.Left), t, v.Name)
+			}
+			return tc.record(node, v.Name)
+		}
+		var l, r = tc.value(node.Left), tc.value(node.Right)
+		return tc.record(node, tc.binary(node, node.Op, l, r))
+	case ast.ASTCallFunc:
+		return tc.value(node)
+	}
+	tc.errorf(node, "%s is not an expression", ast.ExprString(node))
+	return Any
+}
+
+// binary returns the type of l op r.
+func (tc *TypeCheckVisitor) binary(node ast.AST, op string, l, r string) string {
+	var numeric = func(t string) bool { return t == Int || t == Float }
+	switch op {
+	case "&&", "||":
+		return Bool
+	case "==", "!=":
+		if l == r || l == Any || r == Any || numeric(l) && numeric(r) {
+			return Bool
+		}
+	case "<", "<=", ">", ">=":
+		if l == Any || r == Any || numeric(l) && numeric(r) || l == String && r == String {
+			return Bool
+		}
+	default:
+		switch {
+		case l == Any && r == Any:
+			return Any
+		case l == Any:
+			l = r
+		case r == Any:
+			r = l
+		}
+		switch {
+		case l == Int && r == Int:
+			return Int
+		case numeric(l) && numeric(r) && !intOps[op]:
+			return Float
+		case l == String && r == String && op == "+":
+			return String
+		}
+	}
+	tc.errorf(node, "invalid operation: operator %s not defined on %s and %s", op, l, r)
+	return Any
+}
+
+// intOps are the binary operators only defined on ints.
+var intOps = map[string]bool{"%": true, "<<": true, ">>": true, "&": true, "|": true, "^": true}
+
+// assignable reports whether a value of type src can be stored in dst.
+func assignable(dst, src string) bool {
+	return dst == src || dst == Any || src == Any || dst == Float && src == Int
+}
+
+// convertible reports whether a value of type src can be converted to dst
+// with as, by the rules interp.Cast applies at run time: numbers and bools
+// convert to each other and strings only to strings.
+func convertible(dst, src string) bool {
+	var scalar = func(t string) bool { return t == Int || t == Float || t == Bool }
+	switch {
+	case dst == Any || src == Any:
+		return true
+	case scalar(dst):
+		return scalar(src)
+	}
+	return dst == src
+}
+
+// funcValue returns the signature of the function ast refers to, or nil.
+func (tc *TypeCheckVisitor) funcValue(node ast.AST) *Signature {
+	if v, ok := node.(ast.ASTVariable); ok {
+		if s := tc.st.Lookup(v.Name); s != nil {
+			return s.Sig
+		}
+	}
+	return nil
+}
+
+// checkType reports the names in a written type that are not types.
+func (tc *TypeCheckVisitor) checkType(node ast.AST, t string) bool {
+	var ok = true
+	MapTypeNames(t, func(name string) string {
+		if strings.Contains(name, ".") {
+			// imported types are not known to the checker
+			return name
+		}
+		switch name {
+		case Int, Float, String, Bool, Any:
+		default:
+			tc.errorf(node, "undefined: %s", name)
+			ok = false
+		}
+		return name
+	})
+	return ok
+}
+
+// MapTypeNames replaces the type names in a written type by fn(name),
+// exp. the names of map[string][]float are string and float.
+func MapTypeNames(t string, fn func(name string) string) string {
+	var b strings.Builder
+	for i := 0; i < len(t); {
+		var j = i
+		for j < len(t) && (t[j] == '_' || t[j] == '.' || t[j] >= 0x80 ||
+			'a' <= t[j] && t[j] <= 'z' || 'A' <= t[j] && t[j] <= 'Z' || '0' <= t[j] && t[j] <= '9') {
+			j++
+		}
+		if j == i {
+			b.WriteByte(t[i])
+			i++
+			continue
+		}
+		var name = t[i:j]
+		switch {
+		case name == "func" || name == "map", '0' <= name[0] && name[0] <= '9':
+			b.WriteString(name)
+		default:
+			b.WriteString(fn(name))
+		}
+		i = j
+	}
+	return b.String()
+}
+
+// HasAny reports whether any of types is not known.
+func HasAny(types []string) bool {
+	for _, t := range types {
+		if t == Any {
+			return true
+		}
+	}
+	return false
+}
+
+// paramType returns the type of a parameter, untyped parameters take
+// values of any type.
+func paramType(v ast.ASTVariable) string {
+	if v.Type != "" {
+		return v.Type
+	}
+	return Any
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
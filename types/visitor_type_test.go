@@ -0,0 +1,147 @@
+package types
+
+import (
+	"strings"
+	"testing"
+
+	"myc/diag"
+	"myc/lexer"
+	"myc/parser"
+)
+
+// check type checks src and returns the messages of its errors.
+func check(src string) (*TypeCheckVisitor, string) {
+	var p = parser.NewParse(lexer.NewLexer([]byte(src)).LexerToken())
+	var tc = NewTypeCheckVisitor(p.Parse())
+	var msgs []string
+	if diags, ok := tc.Exec().(diag.Diagnostics); ok {
+		for _, d := range diags {
+			msgs = append(msgs, d.Message)
+		}
+	}
+	return tc, strings.Join(msgs, "; ")
+}
+
+func TestInfer(t *testing.T) {
+	var tests = []struct {
+		src  string
+		name string
+		want string
+	}{
+		{"var x = 1\n", "x", Int},
+		{"var x = 1.5 * 2\n", "x", Float},
+		{"var x = \"a\" + \"b\"\n", "x", String},
+		{"var a, b = 1, \"s\"\nvar x = b\n", "x", String},
+		{"func f() (int) { return 1 }\nvar x = f()\n", "x", Int},
+		{"func half(n) { return n / 2.0 }\nvar x = half(3)\n", "x", Float},
+		{"var x float = 1\n", "x", Float},
+		{"var x = 1 < 2 || false\n", "x", Bool},
+		{"var x = 7 % 2 << 1\n", "x", Int},
+		{"var x = 2.5 as int\n", "x", Int},
+		{"var x = -1.5\n", "x", Float},
+		{"func f() (int, string) { return 1, \"a\" }\nvar a, x = f()\n", "x", String},
+	}
+	for _, test := range tests {
+		tc, err := check(test.src)
+		if err != "" {
+			t.Errorf("%q: %s", test.src, err)
+			continue
+		}
+		if s := tc.global.Lookup(test.name); s == nil || s.Type != test.want {
+			t.Errorf("%q: got %v, want %s %s", test.src, s, test.name, test.want)
+		}
+	}
+}
+
+func TestCheckErrors(t *testing.T) {
+	var tests = []struct {
+		src string
+		err string
+	}{
+		// variables keep the type they are declared with
+		{"var a = 1\na = \"s\"\n", "cannot use string value as int in assignment to a"},
+		{"var a = 1\na += 0.5\n", "cannot use float value as int in assignment to a"},
+		{"var a = 1.5\na = 2\n", ""},
+		{"var a = b\n", "undefined: b"},
+		{"c = 1\n", "undefined: c"},
+		{"var a = 1\nvar a = 2\n", "a redeclared in this block"},
+		{"var a, b = 1, 2, 3\n", "assignment mismatch: 2 variables but 3 values"},
+
+		// operand types
+		{"var a = 1 + \"s\"\n", "invalid operation: operator + not defined on int and string"},
+		{"var a = \"s\" * 2\n", "invalid operation: operator * not defined on string and int"},
+		{"var a = \"s\" - \"t\"\n", "invalid operation: operator - not defined on string and string"},
+		{"var a = 1 + 2.5\n", ""},
+
+		// arity
+		{"func f(a, b) { return a }\nf(1)\n", "wrong number of arguments in call to f: have 1, want 2"},
+		{"func f() { return }\nf(1, 2)\n", "wrong number of arguments in call to f: have 2, want 0"},
+		{"var a = 1\na()\n", "cannot call non-function a (int)"},
+		{"g()\n", "undefined: g"},
+		{"func f(a, a) { return }\n", "duplicate argument a"},
+		{"func f() { return }\nfunc f() { return }\n", "f redeclared in this block"},
+
+		// return counts
+		{"func f() (int) { return 1, 2 }\n", "wrong number of return values: have 2, want 1"},
+		{"func f() (int, int) { return 1 }\n", "wrong number of return values: have 1, want 2"},
+		{"func f() (int, int) { return 1, 2 }\nvar a, b = f()\n", ""},
+
+		// expressions are shown as they are written
+		{"func f(a int) { return }\nf(1 + 2.5)\n", "cannot use 1 + 2.5 (float) as int value in argument to f"},
+		{"func f() (int) { return \"a\" + \"b\" }\n", "cannot use \"a\" + \"b\" (string) as int value in return statement"},
+		{"func f() { return }\nvar a = f()\n", "f() (no value) used as value"},
+		{"func f() (int, int) { return 1, 2 }\nvar a = f() + 1\n", "multiple-value f() in single-value context"},
+		{"var a = (1 + 2) * \"s\" as int\n", "cannot convert \"s\" (string) to int"},
+		{"var a = -\"s\"\n", "invalid operation: operator - not defined on \"s\" (string)"},
+		{"for i in 0..\"ten\" {\n}\n", "cannot range over \"ten\" (string)"},
+		{"import \"stdio\"\nstdio.printf(\"%d\\n\", 1.5 * 2)\n", "stdio.printf format %d has argument 1.5 * 2 of wrong type float"},
+
+		// every error is reported
+		{"var a = 1 + \"s\"\nvar b = c\n", "invalid operation: operator + not defined on int and string; undefined: c"},
+	}
+	for _, test := range tests {
+		if _, err := check(test.src); err != test.err {
+			t.Errorf("%q: got %q, want %q", test.src, err, test.err)
+		}
+	}
+}
+
+func TestCheckBool(t *testing.T) {
+	var tests = []struct {
+		src string
+		err string
+	}{
+		{"var a = 1 < 2 && true\na = false\n", ""},
+		{"var a = 1 < 2\na = 1\n", "cannot use int value as bool in assignment to a"},
+		{"var a = true + 1\n", "invalid operation: operator + not defined on bool and int"},
+		{"var a = \"a\" < 1\n", "invalid operation: operator < not defined on string and int"},
+		{"var a = \"a\" == \"b\" || 1 != 2.5\n", ""},
+	}
+	for _, test := range tests {
+		if _, err := check(test.src); err != test.err {
+			t.Errorf("%q: got %q, want %q", test.src, err, test.err)
+		}
+	}
+}
+
+func TestCheckCond(t *testing.T) {
+	var tests = []struct {
+		src string
+		err string
+	}{
+		{"if 1 < 2 {\n}\n", ""},
+		{"var b = true\nif b && !b {\n} else if b {\n}\n", ""},
+		{"func f(a) { return a }\nif f(1) {\n}\n", ""},
+		{"if 1 {\n}\n", "non-bool 1 (int) used as condition"},
+		{"var s = \"a\"\nif true {\n} else if s + \"b\" {\n}\n", "non-bool s + \"b\" (string) used as condition"},
+		{"func f() { return }\nif f() {\n}\n", "f() (no value) used as value"},
+		{"func f() (bool, bool) { return true, false }\nif f() {\n}\n", "multiple-value f() in single-value context"},
+		{"while 0.5 {\n}\n", "non-bool 0.5 (float) used as condition"},
+		{"for var i = 0; i; i += 1 {\n}\n", "non-bool i (int) used as condition"},
+	}
+	for _, test := range tests {
+		if _, err := check(test.src); err != test.err {
+			t.Errorf("%q: got %q, want %q", test.src, err, test.err)
+		}
+	}
+}